@@ -26,7 +26,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	bot.StartHealthServer()
+	bot.StartHealthServer(b)
 
 	if err := b.Start(); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"musicbot/internal/cache"
+	"musicbot/internal/musicapi"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Discord sends an autocomplete request per keystroke. Each one waits
+	// autocompleteDebounce and is dropped if the same user typed again
+	// meanwhile; only the last gets an API search.
+	autocompleteDebounce = 300 * time.Millisecond
+	// autocompleteTimeout keeps the search inside Discord's 3s deadline.
+	autocompleteTimeout = 2500 * time.Millisecond
+	autocompleteMinLen  = 2
+	autocompleteMax     = 25 // Discord's limit on choices
+
+	autocompleteCacheSize = 512
+	autocompleteCacheTTL  = 5 * time.Minute
+
+	// songIDPrefix marks /play query values that are song IDs picked
+	// from autocomplete rather than text to search for.
+	songIDPrefix = "id:"
+)
+
+// autocompleter debounces and caches /play suggestions.
+type autocompleter struct {
+	mu     sync.Mutex
+	seq    uint64
+	latest map[string]uint64 // userID -> seq of their newest request
+
+	cache *cache.LRU[string, []*discordgo.ApplicationCommandOptionChoice]
+}
+
+func newAutocompleter() *autocompleter {
+	return &autocompleter{
+		latest: make(map[string]uint64),
+		cache:  cache.New[string, []*discordgo.ApplicationCommandOptionChoice](autocompleteCacheSize, autocompleteCacheTTL),
+	}
+}
+
+// wait registers a request from userID, sleeps out the debounce and
+// reports whether it is still the user's newest.
+func (ac *autocompleter) wait(ctx context.Context, userID string) bool {
+	ac.mu.Lock()
+	ac.seq++
+	mine := ac.seq
+	ac.latest[userID] = mine
+	ac.mu.Unlock()
+
+	select {
+	case <-time.After(autocompleteDebounce):
+	case <-ctx.Done():
+		return false
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if ac.latest[userID] != mine {
+		return false
+	}
+	delete(ac.latest, userID)
+	return true
+}
+
+func (b *Bot) handlePlayAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := strings.TrimSpace(optionString(i, "query"))
+	if len([]rune(query)) < autocompleteMinLen || strings.HasPrefix(query, songIDPrefix) || isWebURL(query) {
+		respondChoices(s, i, nil)
+		return
+	}
+
+	key := strings.ToLower(query)
+	if choices, ok := b.ac.cache.Get(key); ok {
+		respondChoices(s, i, choices)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, autocompleteTimeout)
+	defer cancel()
+	if !b.ac.wait(ctx, i.Member.User.ID) {
+		return // superseded; Discord only shows the newest answer anyway
+	}
+
+	results, err := b.music.Search(ctx, query)
+	if err != nil {
+		respondChoices(s, i, nil)
+		return
+	}
+	results = musicapi.RankSongs(query, results)
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, autocompleteMax)
+	for _, song := range results {
+		value := songIDPrefix + song.ID
+		if len(value) > 100 {
+			continue // Discord caps values at 100 characters
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(fmt.Sprintf("%s — %s", song.Title, song.Artist), 100),
+			Value: value,
+		})
+		if len(choices) == autocompleteMax {
+			break
+		}
+	}
+	b.ac.cache.Add(key, choices)
+	respondChoices(s, i, choices)
+}
+
+func respondChoices(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"musicbot/internal/musicapi"
+)
+
+const (
+	// historySize is how many recent track IDs autoplay avoids per guild.
+	historySize = 50
+	// autoplayTries caps how many search hits we try to resolve.
+	autoplayTries = 3
+
+	autoplayRequester = "🤖 autoplay"
+)
+
+// trackHistory is a fixed-size ring of recently played track IDs.
+type trackHistory struct {
+	ids  []string
+	next int
+}
+
+func (h *trackHistory) add(id string) {
+	if id == "" {
+		return
+	}
+	if len(h.ids) < historySize {
+		h.ids = append(h.ids, id)
+		return
+	}
+	h.ids[h.next] = id
+	h.next = (h.next + 1) % historySize
+}
+
+func (h *trackHistory) contains(id string) bool {
+	for _, seen := range h.ids {
+		if seen == id {
+			return true
+		}
+	}
+	return false
+}
+
+// remember records a track as played in the guild history.
+func (pm *PlaybackManager) remember(guildID string, d *musicapi.SongDetail) {
+	if d == nil {
+		return
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	h := pm.history[guildID]
+	if h == nil {
+		h = &trackHistory{}
+		pm.history[guildID] = h
+	}
+	h.add(d.ID)
+}
+
+func (pm *PlaybackManager) playedRecently(guildID, id string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	h := pm.history[guildID]
+	return h != nil && h.contains(id)
+}
+
+// autoplay picks a follow-up for last from the provider's related tracks
+// (falling back to searching its artist), skipping anything played
+// recently in the guild. It returns nil when nothing fits.
+func (pm *PlaybackManager) autoplay(ctx context.Context, guildID string, last *QueueItem) *QueueItem {
+	if last == nil || last.Track == nil {
+		return nil
+	}
+	artist := last.Track.PrimaryArtist()
+	if artist == "" {
+		return nil
+	}
+
+	var results []musicapi.SongLite
+	var err error
+	if rp, ok := pm.bot.music.(musicapi.RelatedProvider); ok {
+		results, err = rp.Related(ctx, last.Track)
+	} else {
+		results, err = pm.bot.music.Search(ctx, artist)
+	}
+	if err != nil {
+		log.Printf("autoplay %s: search %q: %v", guildID, artist, err)
+		return nil
+	}
+
+	tries := 0
+	for _, song := range rankForAutoplay(results, artist) {
+		if song.ID == last.Track.ID || pm.playedRecently(guildID, song.ID) {
+			continue
+		}
+		if tries == autoplayTries {
+			break
+		}
+		tries++
+
+		detail, err := pm.bot.music.Resolve(ctx, song.ID)
+		if err != nil {
+			log.Printf("autoplay %s: load %s: %v", guildID, song.ID, err)
+			continue
+		}
+		stream := pm.bot.playableURL(detail)
+		if stream == "" {
+			continue
+		}
+		return &QueueItem{Track: detail, URL: stream, RequestedBy: autoplayRequester}
+	}
+	return nil
+}
+
+// rankForAutoplay moves songs crediting the same artist to the front,
+// keeping the API order otherwise.
+func rankForAutoplay(results []musicapi.SongLite, artist string) []musicapi.SongLite {
+	out := make([]musicapi.SongLite, 0, len(results))
+	var rest []musicapi.SongLite
+	for _, s := range results {
+		if s.HasArtist(artist) {
+			out = append(out, s)
+		} else {
+			rest = append(rest, s)
+		}
+	}
+	return append(out, rest...)
+}
@@ -1,7 +1,10 @@
 package bot
 
 import (
+	"context"
 	"log"
+	"musicbot/internal/cache"
+	"musicbot/internal/library"
 	"musicbot/internal/musicapi"
 	"time"
 
@@ -11,9 +14,23 @@ import (
 type Bot struct {
 	cfg Config
 	dg  *discordgo.Session
-	api *musicapi.Client
+
+	// music is what handlers search and resolve through: the API client,
+	// the local library, or both chained.
+	music musicapi.Provider
+	api   *musicapi.Client  // nil in offline mode
+	lib   *library.Provider // nil without LIBRARY_DIRS
+
+	radio *radioPresets
+	ac    *autocompleter
+
+	searches *cache.LRU[string, *searchSession] // /play result pages by interaction ID
 
 	pm *PlaybackManager
+
+	// ctx is the parent of every API call and player; Close cancels it.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func New(cfg Config) (*Bot, error) {
@@ -22,11 +39,55 @@ func New(cfg Config) (*Bot, error) {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	b := &Bot{
-		cfg: cfg,
-		dg:  dg,
-		api: musicapi.New(cfg.MusicAPIBase, cfg.MusicPrefix),
+		cfg:    cfg,
+		dg:     dg,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	var chain musicapi.Chain
+	if len(cfg.LibraryDirs) > 0 {
+		opts := library.Options{
+			Dirs:      cfg.LibraryDirs,
+			IndexPath: cfg.LibraryIndex,
+			CoverDir:  cfg.LibraryCoverDir,
+		}
+		if cfg.PublicURL != "" {
+			opts.CoverURL = cfg.PublicURL + coversPath
+		}
+		b.lib = library.New(opts)
+		if err := b.lib.Scan(); err != nil {
+			return nil, err
+		}
+		chain = append(chain, b.lib)
+	}
+	if len(cfg.MusicAPIBackends) > 0 {
+		b.api = musicapi.NewMulti(cfg.MusicAPIBackends, cfg.MusicAPIMode)
+		b.api.SetRetryPolicy(cfg.Retry)
+		b.api.SetCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+		if cfg.MusicAPIMapping != nil {
+			if err := b.api.SetMapping(*cfg.MusicAPIMapping); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.Cache != nil {
+			b.api.EnableCache(*cfg.Cache)
+		}
+		chain = append(chain, b.api)
 	}
+	b.music = chain
+	if len(chain) == 1 {
+		b.music = chain[0]
+	}
+
+	if b.radio, err = loadRadioPresets(cfg.RadioPresets); err != nil {
+		return nil, err
+	}
+
+	b.ac = newAutocompleter()
+	b.searches = newSearchSessions()
 	b.pm = NewPlaybackManager(b)
 
 	return b, nil
@@ -37,6 +98,10 @@ func (b *Bot) Start() error {
 	b.dg.AddHandler(b.onReady)
 	b.dg.AddHandler(b.onInteractionCreate)
 
+	if b.lib != nil && b.cfg.LibraryRescan > 0 {
+		go b.lib.Watch(b.ctx, b.cfg.LibraryRescan)
+	}
+
 	var err error
 	for i := 0; i < 5; i++ {
 		err = b.dg.Open()
@@ -50,10 +115,25 @@ func (b *Bot) Start() error {
 }
 
 func (b *Bot) Close() error {
+	b.cancel()
 	b.pm.StopAll()
 	return b.dg.Close()
 }
 
+// interactionTokenLifetime is how long Discord accepts responses and
+// follow-ups for an interaction; work past that point would be wasted.
+const interactionTokenLifetime = 15 * time.Minute
+
+// interactionContext bounds work done on behalf of i by its token lifetime
+// and by the bot shutting down.
+func (b *Bot) interactionContext(i *discordgo.InteractionCreate) (context.Context, context.CancelFunc) {
+	created, err := discordgo.SnowflakeTimestamp(i.ID)
+	if err != nil {
+		created = time.Now()
+	}
+	return context.WithDeadline(b.ctx, created.Add(interactionTokenLifetime))
+}
+
 func (b *Bot) onReady(s *discordgo.Session, r *discordgo.Ready) {
 	log.Printf("Logged in as %s", s.State.User.String())
 }
@@ -2,6 +2,15 @@ package bot
 
 import "github.com/bwmarrin/discordgo"
 
+var (
+	minQueuePos = 1.0
+	minVolume   = 0.0
+	maxVolumeF  = float64(maxVolume)
+
+	minCrossfadeSecs = 0.0
+	maxCrossfadeSecs = maxCrossfade.Seconds()
+)
+
 func RegisterCommands(cfg Config) error {
 	dg, err := discordgo.New("Bot " + cfg.Token)
 	if err != nil {
@@ -16,12 +25,190 @@ func RegisterCommands(cfg Config) error {
 		{
 			Name:        "play",
 			Description: "Search songs, pick one from dropdown, then play in your voice channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "query",
+					Description:  "Song name or artist",
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "album",
+					Description: "Queue a whole album (name, or id:<album id>)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "playlist",
+					Description: "Queue a whole playlist (ID or link)",
+				},
+			},
+		},
+		{
+			Name:        "queue",
+			Description: "Show the current track and what's up next",
+		},
+		{
+			Name:        "skip",
+			Description: "Skip the current track",
+		},
+		{
+			Name:        "remove",
+			Description: "Remove a track from the queue",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "position",
+					Description: "Queue position (see /queue)",
+					Required:    true,
+					MinValue:    &minQueuePos,
+				},
+			},
+		},
+		{
+			Name:        "move",
+			Description: "Move a queued track to another position",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "from",
+					Description: "Current queue position",
+					Required:    true,
+					MinValue:    &minQueuePos,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "to",
+					Description: "New queue position",
+					Required:    true,
+					MinValue:    &minQueuePos,
+				},
+			},
+		},
+		{
+			Name:        "clear",
+			Description: "Remove every upcoming track from the queue",
+		},
+		{
+			Name:        "seek",
+			Description: "Jump to a position in the current track",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "position",
+					Description: "Position as mm:ss (or seconds)",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "volume",
+			Description: "Show or set the playback volume for this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "percent",
+					Description: "Volume from 0 to 200 (100 is normal)",
+					MinValue:    &minVolume,
+					MaxValue:    maxVolumeF,
+				},
+			},
+		},
+		{
+			Name:        "loop",
+			Description: "Repeat the current track, the whole queue, or nothing",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "query",
-					Description: "Song name or artist",
+					Name:        "mode",
+					Description: "Loop mode",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "track", Value: LoopTrack.String()},
+						{Name: "queue", Value: LoopQueue.String()},
+						{Name: "off", Value: LoopOff.String()},
+					},
+				},
+			},
+		},
+		{
+			Name:        "autoplay",
+			Description: "Keep playing related tracks when the queue runs out",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Turn autoplay on or off (toggles when omitted)",
+				},
+			},
+		},
+		{
+			Name:        "crossfade",
+			Description: "Blend the end of each track into the next",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "seconds",
+					Description: "Overlap in seconds, 0 for gapless (max 12)",
 					Required:    true,
+					MinValue:    &minCrossfadeSecs,
+					MaxValue:    maxCrossfadeSecs,
+				},
+			},
+		},
+		{
+			Name:        "radio",
+			Description: "Stream internet radio and manage this server's stations",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "play",
+					Description: "Tune in to a stream URL or a saved station",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "station",
+							Description: "Stream URL or preset name",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "save",
+					Description: "Save a station for this server",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Preset name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "Stream URL",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Remove a saved station",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Preset name",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Show this server's saved stations",
 				},
 			},
 		},
@@ -3,15 +3,51 @@ package bot
 import (
 	"errors"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"musicbot/internal/musicapi"
 )
 
 type Config struct {
-	Token        string
-	GuildID      string
-	MusicAPIBase string
-	MusicPrefix  string
-	FFmpegPath   string
+	Token      string
+	GuildID    string
+	FFmpegPath string
+	// FFprobePath reads metadata of links pasted into /play.
+	FFprobePath string
+
+	// MusicAPIBackends are the API mirrors in order of preference. It may
+	// be empty when LibraryDirs is set (offline mode).
+	MusicAPIBackends []musicapi.Backend
+	MusicAPIMode     musicapi.SearchMode
+	// MusicAPIMapping overrides where response fields are read from; nil
+	// uses musicapi.DefaultMapping.
+	MusicAPIMapping *musicapi.Mapping
+	// RadioPresets is the file storing /radio stations per guild.
+	RadioPresets string
+	// LinkPatterns turn pasted permalinks into API IDs.
+	LinkPatterns []LinkPattern
+	// MaxBitrate caps the stream quality picked for playback, in kbps;
+	// 0 plays the best available.
+	MaxBitrate int
+
+	// LibraryDirs are local directories of audio files to index and play.
+	LibraryDirs     []string
+	LibraryIndex    string        // persistent index file
+	LibraryCoverDir string        // extracted cover art
+	LibraryRescan   time.Duration // 0 disables periodic rescans
+
+	// PublicURL is where the health server is reachable from outside
+	// (used for cover art links); empty disables covers in embeds.
+	PublicURL string
+
+	// Cache is off (nil) unless MUSIC_CACHE=on; MUSIC_CACHE_* size it.
+	Cache *musicapi.CacheOptions
+
+	Retry            musicapi.RetryPolicy
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 func LoadConfigFromEnv() (Config, error) {
@@ -20,12 +56,6 @@ func LoadConfigFromEnv() (Config, error) {
 		return Config{}, errors.New("DISCORD_TOKEN missing")
 	}
 
-	base := strings.TrimSpace(os.Getenv("MUSIC_API_BASE"))
-	if base == "" {
-		return Config{}, errors.New("MUSIC_API_BASE missing")
-	}
-	base = strings.TrimRight(base, "/")
-
 	prefix := strings.TrimSpace(os.Getenv("MUSIC_API_PREFIX"))
 	if prefix == "" {
 		prefix = "/api"
@@ -34,16 +64,121 @@ func LoadConfigFromEnv() (Config, error) {
 		prefix = "/" + prefix
 	}
 
+	// MUSIC_API_BASE is a comma-separated list of mirrors sharing the prefix
+	var backends []musicapi.Backend
+	for _, base := range splitList(os.Getenv("MUSIC_API_BASE")) {
+		base = strings.TrimRight(base, "/")
+		backends = append(backends, musicapi.Backend{Base: base, Prefix: prefix})
+	}
+	libDirs := splitList(os.Getenv("LIBRARY_DIRS"))
+	if len(backends) == 0 && len(libDirs) == 0 {
+		return Config{}, errors.New("MUSIC_API_BASE missing (or set LIBRARY_DIRS to play local files)")
+	}
+
+	mode, err := musicapi.ParseSearchMode(os.Getenv("MUSIC_API_SEARCH_MODE"))
+	if err != nil {
+		return Config{}, err
+	}
+
+	var mapping *musicapi.Mapping
+	if path := strings.TrimSpace(os.Getenv("MUSIC_API_MAPPING")); path != "" {
+		m, err := musicapi.LoadMapping(path)
+		if err != nil {
+			return Config{}, err
+		}
+		mapping = &m
+	}
+
+	links := defaultLinkPatterns
+	if path := strings.TrimSpace(os.Getenv("MUSIC_LINK_PATTERNS")); path != "" {
+		if links, err = LoadLinkPatterns(path); err != nil {
+			return Config{}, err
+		}
+	}
+
 	ff := strings.TrimSpace(os.Getenv("FFMPEG_PATH"))
 	if ff == "" {
 		ff = "ffmpeg"
 	}
 
+	var cacheOpts *musicapi.CacheOptions
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("MUSIC_CACHE")), "on") {
+		cacheOpts = &musicapi.CacheOptions{
+			SearchSize: envInt("MUSIC_CACHE_SEARCH_SIZE", 256),
+			SearchTTL:  envDuration("MUSIC_CACHE_SEARCH_TTL", 10*time.Minute),
+			SongSize:   envInt("MUSIC_CACHE_SONG_SIZE", 1024),
+			SongTTL:    envDuration("MUSIC_CACHE_SONG_TTL", 6*time.Hour),
+		}
+	}
+
 	return Config{
-		Token:        token,
-		GuildID:      strings.TrimSpace(os.Getenv("GUILD_ID")),
-		MusicAPIBase: base,
-		MusicPrefix:  prefix,
-		FFmpegPath:   ff,
+		Token:            token,
+		GuildID:          strings.TrimSpace(os.Getenv("GUILD_ID")),
+		FFmpegPath:       ff,
+		FFprobePath:      envString("FFPROBE_PATH", "ffprobe"),
+		LinkPatterns:     links,
+		RadioPresets:     envString("RADIO_PRESETS", "radio-presets.json"),
+		MusicAPIBackends: backends,
+		MusicAPIMode:     mode,
+		MusicAPIMapping:  mapping,
+		MaxBitrate:       envInt("MUSIC_MAX_BITRATE", 0),
+		LibraryDirs:      libDirs,
+		LibraryIndex:     envString("LIBRARY_INDEX", "library-index.json"),
+		LibraryCoverDir:  envString("LIBRARY_COVER_DIR", "library-covers"),
+		LibraryRescan:    envDurationOrOff("LIBRARY_RESCAN", 10*time.Minute),
+		PublicURL:        strings.TrimRight(strings.TrimSpace(os.Getenv("PUBLIC_URL")), "/"),
+		Cache:            cacheOpts,
+		Retry: musicapi.RetryPolicy{
+			MaxAttempts: envInt("MUSIC_API_RETRIES", musicapi.DefaultRetryPolicy.MaxAttempts),
+			BaseDelay:   envDuration("MUSIC_API_RETRY_BASE", musicapi.DefaultRetryPolicy.BaseDelay),
+			MaxDelay:    envDuration("MUSIC_API_RETRY_MAX", musicapi.DefaultRetryPolicy.MaxDelay),
+		},
+		BreakerThreshold: envInt("MUSIC_API_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  envDuration("MUSIC_API_BREAKER_COOLDOWN", 30*time.Second),
 	}, nil
 }
+
+// splitList splits a comma-separated env value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// envInt reads a positive integer, falling back to def when unset or invalid.
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// envDuration reads a Go duration ("90s", "10m"), falling back to def.
+func envDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+func envString(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// envDurationOrOff is envDuration that also accepts "off" (or "0") as 0.
+func envDurationOrOff(key string, def time.Duration) time.Duration {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "off", "0":
+		return 0
+	}
+	return envDuration(key, def)
+}
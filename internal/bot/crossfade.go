@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+const maxCrossfade = 12 * time.Second
+
+// crossfadeSource plays cur and, once cur runs out, mixes its last frames
+// with the head of the next (prefetched) track. Whatever it consumes from
+// the next track is recorded on the prefetch so that track resumes from
+// the right place when the player moves on to it.
+type crossfadeSource struct {
+	cur     AudioSource
+	n       int // crossfade length in frames
+	partner func() *prefetched
+
+	ahead   [][]int16 // lookahead so we know where the tail starts
+	curDone bool
+	next    *prefetched
+	fadeLen int
+	fadePos int
+	scratch []int16
+}
+
+func newCrossfadeSource(cur AudioSource, n int, partner func() *prefetched) *crossfadeSource {
+	return &crossfadeSource{
+		cur:     cur,
+		n:       n,
+		partner: partner,
+		ahead:   make([][]int16, 0, n+1),
+		scratch: make([]int16, frameSize*channels),
+	}
+}
+
+func (c *crossfadeSource) ReadFrame(dst []int16) error {
+	for !c.curDone && len(c.ahead) <= c.n {
+		f := make([]int16, len(dst))
+		if err := c.cur.ReadFrame(f); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			c.curDone = true
+			c.fadeLen = len(c.ahead)
+			c.next = c.partner()
+			break
+		}
+		c.ahead = append(c.ahead, f)
+	}
+	if len(c.ahead) == 0 {
+		return io.EOF
+	}
+
+	copy(dst, c.ahead[0])
+	c.ahead[0] = nil
+	c.ahead = c.ahead[1:]
+
+	if c.curDone && c.next != nil {
+		c.mix(dst)
+	}
+	return nil
+}
+
+// mix blends the next frame of the upcoming track into dst using an
+// equal-power curve, so loudness stays even through the fade.
+func (c *crossfadeSource) mix(dst []int16) {
+	if err := c.next.src.ReadFrame(c.scratch); err != nil {
+		c.next = nil
+		return
+	}
+	c.next.lead++
+
+	t := (float64(c.fadePos) + 0.5) / float64(c.fadeLen)
+	out, in := math.Cos(t*math.Pi/2), math.Sin(t*math.Pi/2)
+	c.fadePos++
+
+	for i := range dst {
+		x := (float64(dst[i])*out + float64(c.scratch[i])*in) / math.MaxInt16
+		dst[i] = int16(math.Round(softClip(x) * math.MaxInt16))
+	}
+}
+
+func (c *crossfadeSource) Close() error {
+	return c.cur.Close()
+}
+
+// crossfadePartner returns the prefetched track that will play after the
+// current one, or nil if there is nothing to fade into. prefetchLoop has it
+// ready before the fade starts, including for tracks queued mid-song.
+func (pm *PlaybackManager) crossfadePartner(p *Player) *prefetched {
+	if pm.Loop(p.guildID) == LoopTrack {
+		return nil // the current track plays again
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pf := p.prefetch
+	if pf == nil || len(p.queue) == 0 || p.queue[0] != pf.item {
+		return nil
+	}
+	return pf
+}
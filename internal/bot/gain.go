@@ -0,0 +1,41 @@
+package bot
+
+import "math"
+
+// softClipKnee is where soft clipping starts, as a fraction of full scale.
+// Below it samples are scaled linearly; above it they bend towards full
+// scale instead of wrapping around int16.
+const softClipKnee = 0.8
+
+// applyGain scales frame in place by vol percent.
+func applyGain(frame []int16, vol int) {
+	if vol == 100 {
+		return
+	}
+	if vol <= 0 {
+		clear(frame)
+		return
+	}
+
+	gain := float64(vol) / 100
+	if gain < 1 {
+		// attenuation can't overflow
+		for i, s := range frame {
+			frame[i] = int16(math.Round(float64(s) * gain))
+		}
+		return
+	}
+	for i, s := range frame {
+		x := float64(s) / math.MaxInt16 * gain
+		frame[i] = int16(math.Round(softClip(x) * math.MaxInt16))
+	}
+}
+
+func softClip(x float64) float64 {
+	ax := math.Abs(x)
+	if ax <= softClipKnee {
+		return x
+	}
+	y := softClipKnee + (1-softClipKnee)*math.Tanh((ax-softClipKnee)/(1-softClipKnee))
+	return math.Copysign(y, x)
+}
@@ -1,8 +1,12 @@
 package bot
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"strings"
+	"time"
+
+	"musicbot/internal/musicapi"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -10,18 +14,55 @@ import (
 const (
 	playSelectID = "play_select_song"
 
-	ctrlPauseID  = "ctrl_pause"
-	ctrlResumeID = "ctrl_resume"
-	ctrlStopID   = "ctrl_stop"
-	ctrlLeaveID  = "ctrl_leave"
+	ctrlPauseID   = "ctrl_pause"
+	ctrlResumeID  = "ctrl_resume"
+	ctrlStopID    = "ctrl_stop"
+	ctrlLeaveID   = "ctrl_leave"
+	ctrlBackID    = "ctrl_back"
+	ctrlForwardID = "ctrl_forward"
+	ctrlVolDownID = "ctrl_vol_down"
+	ctrlVolUpID   = "ctrl_vol_up"
+	ctrlRepeatID  = "ctrl_repeat"
+
+	seekStep   = 10 * time.Second
+	volumeStep = 10
 )
 
 func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch i.Type {
 
 	case discordgo.InteractionApplicationCommand:
-		if i.ApplicationCommandData().Name == "play" {
+		switch i.ApplicationCommandData().Name {
+		case "play":
 			b.handlePlay(s, i)
+		case "queue":
+			b.handleQueue(s, i)
+		case "skip":
+			b.handleSkip(s, i)
+		case "remove":
+			b.handleRemove(s, i)
+		case "move":
+			b.handleMove(s, i)
+		case "clear":
+			b.handleClear(s, i)
+		case "seek":
+			b.handleSeek(s, i)
+		case "volume":
+			b.handleVolume(s, i)
+		case "loop":
+			b.handleLoop(s, i)
+		case "autoplay":
+			b.handleAutoplay(s, i)
+		case "crossfade":
+			b.handleCrossfade(s, i)
+		case "radio":
+			b.handleRadio(s, i)
+		}
+
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		switch i.ApplicationCommandData().Name {
+		case "play":
+			b.handlePlayAutocomplete(s, i)
 		}
 
 	case discordgo.InteractionMessageComponent:
@@ -38,14 +79,43 @@ func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.Interaction
 			b.handleControl(s, i, "stop")
 		case ctrlLeaveID:
 			b.handleControl(s, i, "leave")
+		case ctrlBackID:
+			b.handleControl(s, i, "back")
+		case ctrlForwardID:
+			b.handleControl(s, i, "forward")
+		case ctrlVolDownID:
+			b.handleControl(s, i, "voldown")
+		case ctrlVolUpID:
+			b.handleControl(s, i, "volup")
+		case ctrlRepeatID:
+			b.handleControl(s, i, "repeat")
+		default:
+			if id, delta, ok := searchPageAction(cid); ok {
+				b.handleSearchPage(s, i, id, delta)
+			}
 		}
 	}
 }
 
 func (b *Bot) handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	query := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
+	for _, kind := range []string{"album", "playlist"} {
+		if ref := strings.TrimSpace(optionString(i, kind)); ref != "" {
+			b.handlePlayCollection(s, i, kind, ref)
+			return
+		}
+	}
+
+	query := strings.TrimSpace(optionString(i, "query"))
 	if query == "" {
-		replyText(s, i, "Give me a song name or artist.")
+		replyText(s, i, "Give me a song name or artist, or an `album` or `playlist`.")
+		return
+	}
+	if isWebURL(query) {
+		b.handlePlayLink(s, i, query)
+		return
+	}
+	if id, ok := strings.CutPrefix(query, songIDPrefix); ok {
+		b.handlePlayID(s, i, strings.TrimSpace(id))
 		return
 	}
 
@@ -54,48 +124,86 @@ func (b *Bot) handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 
-	results, err := b.api.SearchSongs(query)
-	if err != nil {
-		editReplyText(s, i, "API error: "+err.Error())
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	ss := &searchSession{query: query, seen: make(map[string]bool)}
+	if err := ss.fill(ctx, b.music, 0); err != nil {
+		editReplyText(s, i, apiErrorText(err))
 		return
 	}
-	if len(results) == 0 {
+	if len(ss.results) == 0 {
 		editReplyText(s, i, "No results found.")
 		return
 	}
-	if len(results) > 25 {
-		results = results[:25]
-	}
+	b.searches.Add(i.ID, ss)
 
-	opts := make([]discordgo.SelectMenuOption, 0, len(results))
-	for _, song := range results {
-		label := truncate(fmt.Sprintf("%s — %s", song.Title, song.Artist), 100)
-		desc := truncate(song.Artist, 100)
-		opts = append(opts, discordgo.SelectMenuOption{
-			Label:       label,
-			Description: desc,
-			Value:       song.ID,
-		})
-	}
+	embeds, components := ss.message(i.ID)
+	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+}
 
-	menu := discordgo.SelectMenu{
-		CustomID:    playSelectID,
-		Placeholder: "Pick a track to play…",
-		Options:     opts,
+// handlePlayID plays a song picked from autocomplete, skipping the search.
+func (b *Bot) handlePlayID(s *discordgo.Session, i *discordgo.InteractionCreate, id string) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	detail, err := b.music.Resolve(ctx, id)
+	if err != nil {
+		followupText(s, i, apiErrorText(err))
+		return
 	}
+	b.queueTrack(s, i, detail)
+}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "Search Results",
-		Description: fmt.Sprintf("Query: **%s**\nSelect a track below.", query),
-		Color:       uiColor,
+// handlePlayLink plays a pasted link: permalinks matching LinkPatterns go
+// through the API, anything else is treated as a direct audio URL and
+// described by ffprobe.
+func (b *Bot) handlePlayLink(s *discordgo.Session, i *discordgo.InteractionCreate, link string) {
+	kind, id, known := matchLink(b.cfg.LinkPatterns, link)
+	switch {
+	case known && kind == "album":
+		b.handlePlayCollection(s, i, kind, "id:"+id)
+		return
+	case known && kind == "playlist":
+		b.handlePlayCollection(s, i, kind, id)
+		return
 	}
 
-	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds: &[]*discordgo.MessageEmbed{embed},
-		Components: &[]discordgo.MessageComponent{
-			discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
-		},
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
+
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	var detail *musicapi.SongDetail
+	var err error
+	if known {
+		detail, err = b.music.Resolve(ctx, id)
+		if err != nil {
+			followupText(s, i, apiErrorText(err))
+			return
+		}
+	} else {
+		detail, err = b.probeURL(ctx, link)
+		if errors.Is(err, errPrivateAddress) {
+			followupText(s, i, "I can only play links to public addresses.")
+			return
+		}
+		if err != nil {
+			log.Printf("play link %s: %v", link, err)
+			followupText(s, i, "Couldn’t find any audio at that link.")
+			return
+		}
+	}
+	b.queueTrack(s, i, detail)
 }
 
 func (b *Bot) handlePickSong(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -117,12 +225,25 @@ func (b *Bot) handlePickSong(s *discordgo.Session, i *discordgo.InteractionCreat
 		},
 	})
 
-	detail, err := b.api.GetSongByID(id)
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	detail, err := b.music.Resolve(ctx, id)
 	if err != nil {
+		if errors.Is(err, musicapi.ErrCircuitOpen) {
+			followupText(s, i, apiErrorText(err))
+			return
+		}
 		followupText(s, i, "Couldn’t load song details: "+err.Error())
 		return
 	}
+	b.queueTrack(s, i, detail)
+}
 
+// queueTrack queues one resolved track for the user's voice channel and
+// answers with the player (when it starts now) or its queue position. The
+// interaction must already be acknowledged; replies go out as followups.
+func (b *Bot) queueTrack(s *discordgo.Session, i *discordgo.InteractionCreate, detail *musicapi.SongDetail) {
 	guildID := i.GuildID
 	userID := i.Member.User.ID
 
@@ -133,10 +254,7 @@ func (b *Bot) handlePickSong(s *discordgo.Session, i *discordgo.InteractionCreat
 	}
 
 	// Pick a playable URL
-	stream := detail.StreamURL
-	if stream == "" {
-		stream = detail.Link
-	}
+	stream := b.playableURL(detail)
 	if stream == "" {
 		followupText(s, i, "No playable audio URL found for this track.")
 		return
@@ -144,20 +262,25 @@ func (b *Bot) handlePickSong(s *discordgo.Session, i *discordgo.InteractionCreat
 
 	requestedBy := "@" + i.Member.User.Username
 
-	// Start playback FIRST (so controls actually work)
-	if err := b.pm.Start(guildID, vcID, stream, detail, requestedBy); err != nil {
+	// Queue FIRST (so controls actually work once it starts)
+	pos, err := b.pm.Enqueue(guildID, vcID, &QueueItem{
+		Track:       detail,
+		URL:         stream,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
 		followupText(s, i, "Playback error: "+err.Error())
 		return
 	}
+	if pos > 0 {
+		followupEmbed(s, i, QueuedEmbed(detail, pos, requestedBy))
+		return
+	}
 
 	// Send modern player UI (embed + controls)
-	embed := NowPlayingEmbed(detail, UIState{
-		Status:      "Playing",
-		VoiceChanID: vcID,
-		RequestedBy: requestedBy,
-	})
+	embed := NowPlayingEmbed(detail, b.uiState(guildID, detail, "Playing", vcID, requestedBy))
 
-	components := PlayerControls(false)
+	components := PlayerControls(false, b.pm.Loop(guildID))
 
 	_, _ = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
 		Embeds:     []*discordgo.MessageEmbed{embed},
@@ -183,6 +306,16 @@ func (b *Bot) handleControl(s *discordgo.Session, i *discordgo.InteractionCreate
 	case "leave":
 		b.pm.Stop(guildID)
 		b.pm.Leave(guildID)
+	case "back":
+		b.pm.SeekBy(guildID, -seekStep)
+	case "forward":
+		b.pm.SeekBy(guildID, seekStep)
+	case "voldown":
+		b.pm.AdjustVolume(guildID, -volumeStep)
+	case "volup":
+		b.pm.AdjustVolume(guildID, volumeStep)
+	case "repeat":
+		b.pm.CycleLoop(guildID)
 	}
 
 	// Update the message UI to reflect status + toggle button
@@ -205,18 +338,59 @@ func (b *Bot) handleControl(s *discordgo.Session, i *discordgo.InteractionCreate
 	status := "Playing"
 	if paused {
 		status = "Paused"
+	} else if b.pm.IsLive(guildID) {
+		status = "Live"
 	}
 
-	embed := NowPlayingEmbed(track, UIState{
-		Status:      status,
-		VoiceChanID: vcID,
-		RequestedBy: requestedBy,
-	})
+	embed := NowPlayingEmbed(track, b.uiState(guildID, track, status, vcID, requestedBy))
 
-	comps := PlayerControls(paused)
+	comps := PlayerControls(paused, b.pm.Loop(guildID))
 
 	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Embeds:     &[]*discordgo.MessageEmbed{embed},
 		Components: &comps,
 	})
 }
+
+// uiState collects the live player settings shown in the now playing embed.
+func (b *Bot) uiState(guildID string, d *musicapi.SongDetail, status, vcID, requestedBy string) UIState {
+	return UIState{
+		Status:      status,
+		VoiceChanID: vcID,
+		RequestedBy: requestedBy,
+		Elapsed:     b.pm.Position(guildID),
+		Duration:    d.Duration,
+		Live:        b.pm.IsLive(guildID),
+		Volume:      b.pm.Volume(guildID),
+		Loop:        b.pm.Loop(guildID),
+		Autoplay:    b.pm.Autoplay(guildID),
+	}
+}
+
+// apiErrorText turns a music API error into something worth showing users.
+func apiErrorText(err error) string {
+	if errors.Is(err, musicapi.ErrCircuitOpen) {
+		return "The music service is down right now. Please try again in a minute."
+	}
+	return "API error: " + err.Error()
+}
+
+// playableURL picks the stream to play: the best quality within
+// MaxBitrate, else the direct stream, else the song page.
+func (b *Bot) playableURL(d *musicapi.SongDetail) string {
+	if urls := d.StreamCandidates(b.cfg.MaxBitrate); len(urls) > 0 {
+		return urls[0]
+	}
+	return ""
+}
+
+// fallbackURL returns the candidate after failed, or "" when there is none.
+func (b *Bot) fallbackURL(d *musicapi.SongDetail, failed string) string {
+	urls := d.StreamCandidates(b.cfg.MaxBitrate)
+	for n, u := range urls {
+		if u == failed && n+1 < len(urls) {
+			return urls[n+1]
+		}
+	}
+	return ""
+}
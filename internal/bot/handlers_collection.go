@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"musicbot/internal/musicapi"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxCollectionTracks caps how many tracks one album or playlist queues.
+const maxCollectionTracks = 100
+
+// handlePlayCollection queues every track of an album or playlist.
+// kind is the /play option that was used ("album" or "playlist").
+func (b *Bot) handlePlayCollection(s *discordgo.Session, i *discordgo.InteractionCreate, kind, ref string) {
+	if b.api == nil {
+		replyText(s, i, "Albums and playlists need the music API; only single tracks play from the local library.")
+		return
+	}
+	vcID, err := b.userVoiceChannelID(i.GuildID, i.Member.User.ID)
+	if err != nil || vcID == "" {
+		replyText(s, i, "Join a **voice channel** first, then use `/play` again.")
+		return
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	coll, err := b.loadCollection(ctx, kind, ref)
+	if err != nil {
+		if errors.Is(err, musicapi.ErrNotFound) {
+			editReplyText(s, i, fmt.Sprintf("No %s found for **%s**.", kind, musicapi.EscapeMarkdown(ref)))
+			return
+		}
+		editReplyText(s, i, apiErrorText(err))
+		return
+	}
+	if len(coll.Songs) == 0 {
+		editReplyText(s, i, fmt.Sprintf("That %s has no playable tracks.", kind))
+		return
+	}
+
+	songs := coll.Songs
+	if len(songs) > maxCollectionTracks {
+		songs = songs[:maxCollectionTracks]
+	}
+	requestedBy := "@" + i.Member.User.Username
+
+	queued, firstPos := 0, -1
+	for n := range songs {
+		d := songs[n]
+		pos, err := b.pm.Enqueue(i.GuildID, vcID, &QueueItem{
+			Track:       &d,
+			URL:         b.playableURL(&d), // may be empty; resolved when it comes up
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			if queued == 0 {
+				editReplyText(s, i, "Playback error: "+err.Error())
+				return
+			}
+			break
+		}
+		if firstPos < 0 {
+			firstPos = pos
+		}
+		queued++
+	}
+
+	embed := CollectionQueuedEmbed(coll, kind, queued, len(coll.Songs)-queued, firstPos, requestedBy)
+	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+// loadCollection finds the album or playlist a /play option refers to.
+// Albums are searched by name unless given as "id:<id>"; playlists take an
+// ID or a link ending in one.
+func (b *Bot) loadCollection(ctx context.Context, kind, ref string) (*musicapi.Collection, error) {
+	if kind == "playlist" {
+		return b.api.GetPlaylist(ctx, collectionID(ref))
+	}
+
+	if id, ok := strings.CutPrefix(ref, "id:"); ok {
+		return b.api.GetAlbum(ctx, strings.TrimSpace(id))
+	}
+	albums, err := b.api.SearchAlbums(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(albums) == 0 {
+		return nil, musicapi.ErrNotFound
+	}
+	hit := bestAlbum(albums, ref)
+	coll, err := b.api.GetAlbum(ctx, hit.ID)
+	if err != nil {
+		return nil, err
+	}
+	// album pages don't always repeat what the search said
+	if coll.Title == "" {
+		coll.Title = hit.Title
+	}
+	if coll.Artist == "" {
+		coll.Artist = hit.Artist
+	}
+	if coll.Image == "" {
+		coll.Image = hit.Image
+	}
+	return coll, nil
+}
+
+// bestAlbum prefers an exact title match and otherwise trusts the API order.
+func bestAlbum(albums []musicapi.Collection, query string) musicapi.Collection {
+	for _, a := range albums {
+		if strings.EqualFold(a.Title, strings.TrimSpace(query)) {
+			return a
+		}
+	}
+	return albums[0]
+}
+
+// collectionID accepts a bare ID or a link and returns the ID, which is the
+// last path segment of links.
+func collectionID(ref string) string {
+	ref = strings.TrimSpace(ref)
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" {
+		return ref
+	}
+	return path.Base(strings.TrimRight(u.Path, "/"))
+}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (b *Bot) handleSeek(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	pos, err := parsePosition(optionString(i, "position"))
+	if err != nil {
+		replyText(s, i, err.Error())
+		return
+	}
+	if !b.pm.Seek(i.GuildID, pos) {
+		replyText(s, i, "Nothing seekable is playing (live radio can't seek).")
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Seeking to `%s`.", formatDuration(pos)))
+}
+
+func (b *Bot) handleVolume(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasOption(i, "percent") {
+		replyText(s, i, fmt.Sprintf("Volume is `%d%%`.", b.pm.Volume(i.GuildID)))
+		return
+	}
+	vol := b.pm.SetVolume(i.GuildID, optionInt(i, "percent"))
+	replyText(s, i, fmt.Sprintf("Volume set to `%d%%`.", vol))
+}
+
+func (b *Bot) handleLoop(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	mode, err := ParseLoopMode(optionString(i, "mode"))
+	if err != nil {
+		replyText(s, i, err.Error())
+		return
+	}
+	b.pm.SetLoop(i.GuildID, mode)
+	replyText(s, i, fmt.Sprintf("Loop mode: `%s`.", mode))
+}
+
+func (b *Bot) handleAutoplay(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	on := !b.pm.Autoplay(i.GuildID)
+	if hasOption(i, "enabled") {
+		on = optionBool(i, "enabled")
+	}
+	b.pm.SetAutoplay(i.GuildID, on)
+	if on {
+		replyText(s, i, "Autoplay is `on`: I'll keep going with related tracks when the queue runs out.")
+		return
+	}
+	replyText(s, i, "Autoplay is `off`.")
+}
+
+func (b *Bot) handleCrossfade(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	d := b.pm.SetCrossfade(i.GuildID, time.Duration(optionInt(i, "seconds"))*time.Second)
+	if d == 0 {
+		replyText(s, i, "Crossfade is `off`: tracks play back to back.")
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Crossfade set to `%ds`.", int(d.Seconds())))
+}
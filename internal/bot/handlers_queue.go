@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (b *Bot) handleQueue(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	current, upcoming := b.pm.Queue(i.GuildID)
+	if current == nil && len(upcoming) == 0 {
+		replyText(s, i, "The queue is empty. Use `/play` to add something.")
+		return
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{QueueEmbed(current, upcoming)},
+		},
+	})
+}
+
+func (b *Bot) handleSkip(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	skipped, ok := b.pm.Skip(i.GuildID)
+	if !ok {
+		replyText(s, i, "Nothing is playing.")
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Skipped **%s**.", trackTitle(skipped)))
+}
+
+func (b *Bot) handleRemove(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	pos := optionInt(i, "position")
+	removed, err := b.pm.Remove(i.GuildID, pos)
+	if err != nil {
+		replyText(s, i, fmt.Sprintf("Can't remove #%d: %s.", pos, err))
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Removed **%s** from the queue.", trackTitle(removed)))
+}
+
+func (b *Bot) handleMove(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	from, to := optionInt(i, "from"), optionInt(i, "to")
+	if err := b.pm.Move(i.GuildID, from, to); err != nil {
+		replyText(s, i, fmt.Sprintf("Can't move #%d to #%d: %s.", from, to, err))
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Moved track #%d to #%d.", from, to))
+}
+
+func (b *Bot) handleClear(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	n := b.pm.Clear(i.GuildID)
+	if n == 0 {
+		replyText(s, i, "The queue is already empty.")
+		return
+	}
+	replyText(s, i, fmt.Sprintf("Cleared %d queued track(s).", n))
+}
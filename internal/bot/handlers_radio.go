@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"musicbot/internal/musicapi"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (b *Bot) handleRadio(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	sub, opts := subcommand(i)
+	str := func(name string) string {
+		if o := opts[name]; o != nil {
+			return strings.TrimSpace(o.StringValue())
+		}
+		return ""
+	}
+
+	switch sub {
+	case "play":
+		b.handleRadioPlay(s, i, str("station"))
+	case "save":
+		name, link := str("name"), str("url")
+		if !isWebURL(link) {
+			replyText(s, i, "That doesn't look like a stream URL (http or https).")
+			return
+		}
+		if err := b.radio.Set(i.GuildID, name, link); err != nil {
+			if errors.Is(err, errTooManyPresets) {
+				replyText(s, i, fmt.Sprintf("This server already has %d stations. Delete one first.", maxRadioPresets))
+				return
+			}
+			replyText(s, i, "Couldn't save the station: "+err.Error())
+			return
+		}
+		replyText(s, i, fmt.Sprintf("Saved station **%s**.", musicapi.EscapeMarkdown(presetKey(name))))
+	case "delete":
+		name := str("name")
+		ok, err := b.radio.Delete(i.GuildID, name)
+		switch {
+		case err != nil:
+			replyText(s, i, "Couldn't delete the station: "+err.Error())
+		case !ok:
+			replyText(s, i, fmt.Sprintf("No station called **%s**.", musicapi.EscapeMarkdown(name)))
+		default:
+			replyText(s, i, fmt.Sprintf("Deleted station **%s**.", musicapi.EscapeMarkdown(presetKey(name))))
+		}
+	case "list":
+		presets := b.radio.List(i.GuildID)
+		if len(presets) == 0 {
+			replyText(s, i, "No stations saved yet. Add one with `/radio save`.")
+			return
+		}
+		var sb strings.Builder
+		for _, p := range presets {
+			fmt.Fprintf(&sb, "• **%s** — <%s>\n", musicapi.EscapeMarkdown(p.Name), p.URL)
+		}
+		replyText(s, i, sb.String())
+	}
+}
+
+// handleRadioPlay queues a live stream. When it starts right away the
+// player goes out as a regular channel message now; a station queued
+// behind other tracks has watchNowPlaying post it when it starts.
+func (b *Bot) handleRadioPlay(s *discordgo.Session, i *discordgo.InteractionCreate, station string) {
+	name := ""
+	link, preset := b.radio.Get(i.GuildID, station)
+	if preset {
+		name = presetKey(station)
+	} else if isWebURL(station) {
+		link = station
+	} else {
+		replyText(s, i, "Unknown station. Give a stream URL or a name from `/radio list`.")
+		return
+	}
+
+	vcID, err := b.userVoiceChannelID(i.GuildID, i.Member.User.ID)
+	if err != nil || vcID == "" {
+		replyText(s, i, "Join a **voice channel** first, then use `/radio` again.")
+		return
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	ctx, cancel := b.interactionContext(i)
+	err = checkPublicURL(ctx, link)
+	cancel()
+	if err != nil {
+		followupText(s, i, "I can only play stations at public addresses.")
+		return
+	}
+
+	if name == "" {
+		if u, err := url.Parse(link); err == nil {
+			name = u.Host
+		}
+	}
+	detail := &musicapi.SongDetail{
+		SongLite: musicapi.SongLite{ID: link, Title: name, Link: link, StreamURL: link},
+		Album:    name,
+	}
+	requestedBy := "@" + i.Member.User.Username
+	item := &QueueItem{Track: detail, URL: link, RequestedBy: requestedBy, Live: true}
+
+	pos, err := b.pm.Enqueue(i.GuildID, vcID, item)
+	if err != nil {
+		followupText(s, i, "Playback error: "+err.Error())
+		return
+	}
+	if pos > 0 {
+		b.pm.AnnounceIn(i.GuildID, item, i.ChannelID)
+		followupEmbed(s, i, QueuedEmbed(detail, pos, requestedBy))
+		return
+	}
+
+	msg, err := b.postLivePlayer(i.ChannelID, i.GuildID, item)
+	if err != nil {
+		// no permission to post in the channel: fall back to a reply,
+		// which won't follow metadata changes
+		ui := b.uiState(i.GuildID, detail, "Live", vcID, requestedBy)
+		ui.Live = true
+		_, _ = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Embeds:     []*discordgo.MessageEmbed{NowPlayingEmbed(detail, ui)},
+			Components: PlayerControls(false, b.pm.Loop(i.GuildID)),
+		})
+		return
+	}
+	followupText(s, i, fmt.Sprintf("📻 Tuned in to **%s**.", musicapi.EscapeMarkdown(name)))
+	b.pm.SetNowPlaying(i.GuildID, item, messageRef{ChannelID: msg.ChannelID, MessageID: msg.ID})
+}
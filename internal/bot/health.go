@@ -1,12 +1,21 @@
 package bot
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+
+	"musicbot/internal/musicapi"
 )
 
-func StartHealthServer() {
+// coversPath is where local library cover art is served.
+const coversPath = "/covers"
+
+// StartHealthServer serves a liveness probe on / and runtime counters (such
+// as music API cache hits/misses) as JSON on /stats. With a local library
+// it also serves extracted cover art under /covers/.
+func StartHealthServer(b *Bot) {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "10000"
@@ -18,9 +27,31 @@ func StartHealthServer() {
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte("ok"))
 		})
+		if b.lib != nil {
+			mux.Handle(coversPath+"/", http.StripPrefix(coversPath, b.lib.CoverHandler()))
+		}
+		mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(b.Stats())
+		})
 
 		addr := "0.0.0.0:" + port
 		log.Printf("Health server listening on %s", addr)
 		_ = http.ListenAndServe(addr, mux)
 	}()
 }
+
+// Stats is the payload of the /stats endpoint.
+type Stats struct {
+	Cache *musicapi.CacheStats `json:"cache,omitempty"`
+}
+
+func (b *Bot) Stats() Stats {
+	var st Stats
+	if b.api != nil {
+		if cs, ok := b.api.CacheStats(); ok {
+			st.Cache = &cs
+		}
+	}
+	return st
+}
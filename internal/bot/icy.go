@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"musicbot/internal/musicapi"
+)
+
+// liveHTTP connects to radio streams. There is no overall timeout since
+// the response never ends; only the wait for headers is bounded.
+var liveHTTP = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+}
+
+// liveMeta is what a stream tells us about itself: the station name from
+// the response headers and, as it changes, the current StreamTitle.
+type liveMeta struct {
+	Station string
+	Title   string
+}
+
+// liveSource decodes an endless HTTP/Icecast stream. The bot reads the
+// HTTP response itself so it can strip the ICY metadata blocks (reporting
+// them through onMeta) and pipes the bare audio into ffmpeg.
+type liveSource struct {
+	*ffmpegSource
+	body io.Closer
+	pw   *os.File
+}
+
+// openLiveSource connects to a radio stream for as long as ctx lasts. HLS
+// playlists are handed to ffmpeg directly; they carry no ICY metadata.
+func (b *Bot) openLiveSource(ctx context.Context, streamURL string, onMeta func(liveMeta)) (AudioSource, error) {
+	if strings.Contains(strings.ToLower(streamURL), ".m3u8") {
+		return startFFmpegSource(ctx, b.cfg.FFmpegPath, streamURL, 0)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Icy-MetaData", "1")
+	req.Header.Set("User-Agent", "musicbot")
+	resp, err := liveHTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("radio %s: %s", streamURL, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); strings.Contains(ct, "mpegurl") {
+		_ = resp.Body.Close()
+		return startFFmpegSource(ctx, b.cfg.FFmpegPath, streamURL, 0)
+	}
+
+	if station := icyText(resp.Header.Get("icy-name")); station != "" {
+		onMeta(liveMeta{Station: station})
+	}
+	var audio io.Reader = resp.Body
+	if n, _ := strconv.Atoi(resp.Header.Get("icy-metaint")); n > 0 {
+		audio = &icyReader{r: bufio.NewReader(resp.Body), metaint: n, left: n, onTitle: func(title string) {
+			onMeta(liveMeta{Title: title})
+		}}
+	}
+
+	// ffmpeg gets a real pipe rather than an io.Reader so killing it never
+	// waits on a read from the network.
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	go func() {
+		_, _ = io.Copy(pw, audio)
+		_ = pw.Close()
+	}()
+
+	src, err := startFFmpeg(ctx, b.cfg.FFmpegPath, []string{"-i", "pipe:0"}, pr)
+	_ = pr.Close() // ffmpeg has its own copy
+	if err != nil {
+		_ = resp.Body.Close()
+		_ = pw.Close()
+		if errors.Is(err, errNoAudio) {
+			err = fmt.Errorf("%w: %s", err, streamURL)
+		}
+		return nil, err
+	}
+	return &liveSource{ffmpegSource: src, body: resp.Body, pw: pw}, nil
+}
+
+func (s *liveSource) Close() error {
+	_ = s.body.Close()
+	_ = s.pw.Close()
+	return s.ffmpegSource.Close()
+}
+
+// icyReader strips the metadata blocks Icecast/SHOUTcast interleave every
+// metaint bytes of audio when asked with "Icy-MetaData: 1".
+type icyReader struct {
+	r       *bufio.Reader
+	metaint int
+	left    int // audio bytes until the next metadata block
+	title   string
+	onTitle func(string)
+}
+
+func (ir *icyReader) Read(p []byte) (int, error) {
+	if ir.left == 0 {
+		if err := ir.readMeta(); err != nil {
+			return 0, err
+		}
+		ir.left = ir.metaint
+	}
+	if len(p) > ir.left {
+		p = p[:ir.left]
+	}
+	n, err := ir.r.Read(p)
+	ir.left -= n
+	return n, err
+}
+
+// readMeta consumes one block: a length byte (in units of 16) followed by
+// text such as "StreamTitle='Artist - Song';StreamUrl=”;".
+func (ir *icyReader) readMeta() error {
+	size, err := ir.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+	block := make([]byte, int(size)*16)
+	if _, err := io.ReadFull(ir.r, block); err != nil {
+		return err
+	}
+	if title, ok := parseStreamTitle(block); ok && title != ir.title {
+		ir.title = title
+		ir.onTitle(title)
+	}
+	return nil
+}
+
+var streamTitleRe = regexp.MustCompile(`StreamTitle='(.*?)';`)
+
+func parseStreamTitle(block []byte) (string, bool) {
+	m := streamTitleRe.FindSubmatch(block)
+	if m == nil {
+		return "", false
+	}
+	return icyText(string(m[1])), true
+}
+
+// icyText cleans ICY strings, which are often Latin-1 rather than UTF-8.
+func icyText(s string) string {
+	if !utf8.ValidString(s) {
+		runes := make([]rune, len(s))
+		for n := 0; n < len(s); n++ {
+			runes[n] = rune(s[n])
+		}
+		s = string(runes)
+	}
+	return musicapi.CleanText(s)
+}
+
+// applyLiveMeta returns a copy of a radio track updated with meta. The
+// station stays in Album; "Artist - Song" titles are split.
+func applyLiveMeta(d *musicapi.SongDetail, meta liveMeta) *musicapi.SongDetail {
+	cp := *d
+	if meta.Station != "" {
+		cp.Album = meta.Station
+		if cp.Title == "" || cp.Title == cp.Link {
+			cp.Title = meta.Station
+		}
+	}
+	if meta.Title != "" {
+		cp.Title = meta.Title
+		cp.Artists, cp.Artist = nil, ""
+		if artist, song, ok := strings.Cut(meta.Title, " - "); ok && strings.TrimSpace(song) != "" {
+			cp.Title = strings.TrimSpace(song)
+			cp.Artists = musicapi.SplitArtists(artist)
+			cp.Artist = musicapi.JoinArtists(cp.Artists)
+		}
+	}
+	return &cp
+}
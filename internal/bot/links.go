@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// LinkPattern maps permalinks of the music service to API IDs. Pattern is
+// a regular expression with a named group "id"; Kind is "song", "album" or
+// "playlist".
+type LinkPattern struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// defaultLinkPatterns cover the JioSaavn-style permalinks our API mirrors.
+var defaultLinkPatterns = []LinkPattern{
+	{Kind: "song", Pattern: `^https?://(?:www\.)?jiosaavn\.com/song/[^/]+/(?P<id>[^/?#]+)`},
+	{Kind: "album", Pattern: `^https?://(?:www\.)?jiosaavn\.com/album/[^/]+/(?P<id>[^/?#]+)`},
+	{Kind: "playlist", Pattern: `^https?://(?:www\.)?jiosaavn\.com/(?:featured|s/playlist)/(?:[^/]+/)*(?P<id>[^/?#]+)`},
+}
+
+// LoadLinkPatterns reads a JSON array of patterns, replacing the defaults.
+func LoadLinkPatterns(path string) ([]LinkPattern, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pats []LinkPattern
+	if err := json.Unmarshal(raw, &pats); err != nil {
+		return nil, fmt.Errorf("link patterns %s: %w", path, err)
+	}
+	if err := compileLinkPatterns(pats); err != nil {
+		return nil, fmt.Errorf("link patterns %s: %w", path, err)
+	}
+	return pats, nil
+}
+
+func compileLinkPatterns(pats []LinkPattern) error {
+	for n := range pats {
+		p := &pats[n]
+		switch p.Kind {
+		case "song", "album", "playlist":
+		default:
+			return fmt.Errorf("pattern %d: unknown kind %q", n+1, p.Kind)
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %d: %w", n+1, err)
+		}
+		if re.SubexpIndex("id") < 0 {
+			return fmt.Errorf("pattern %d: no (?P<id>...) group", n+1)
+		}
+		p.re = re
+	}
+	return nil
+}
+
+func init() {
+	if err := compileLinkPatterns(defaultLinkPatterns); err != nil {
+		panic(err)
+	}
+}
+
+// matchLink returns the kind and ID of a known permalink.
+func matchLink(pats []LinkPattern, link string) (kind, id string, ok bool) {
+	for _, p := range pats {
+		if m := p.re.FindStringSubmatch(link); m != nil {
+			if id := m[p.re.SubexpIndex("id")]; id != "" {
+				return p.Kind, id, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// isWebURL reports whether a /play query is an http(s) link.
+func isWebURL(s string) bool {
+	if strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+var errPrivateAddress = errors.New("link points at a private or local address")
+
+// checkPublicURL resolves the host of a user-supplied link and rejects it
+// when any address is loopback, private, link-local or otherwise not on the
+// public internet, so pasted links can't make the bot fetch from its own
+// network.
+func checkPublicURL(ctx context.Context, link string) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	addrs := []netip.Addr{}
+	if a, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, a)
+	} else {
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return err
+		}
+		addrs = ips
+	}
+	for _, a := range addrs {
+		a = a.Unmap()
+		// not global unicast: loopback, link-local, multicast, unspecified
+		if !a.IsGlobalUnicast() || a.IsPrivate() || sharedAddressSpace.Contains(a) {
+			return fmt.Errorf("%w: %s", errPrivateAddress, host)
+		}
+	}
+	return nil
+}
+
+// sharedAddressSpace is carrier-grade NAT (RFC 6598), which IsPrivate
+// doesn't cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
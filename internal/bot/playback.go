@@ -3,24 +3,32 @@ package bot
 import (
 	"context"
 	"errors"
+	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"musicbot/internal/musicapi"
 
 	"github.com/bwmarrin/discordgo"
+	"layeh.com/gopus"
 )
 
 type PlaybackManager struct {
 	bot *Bot
 
-	mu      sync.Mutex
-	players map[string]*Player // guildID -> player
+	mu       sync.Mutex
+	players  map[string]*Player        // guildID -> player
+	settings map[string]*guildSettings // guildID -> settings
+	history  map[string]*trackHistory  // guildID -> recently played IDs
 }
 
 func NewPlaybackManager(b *Bot) *PlaybackManager {
 	return &PlaybackManager{
-		bot:     b,
-		players: make(map[string]*Player),
+		bot:      b,
+		players:  make(map[string]*Player),
+		settings: make(map[string]*guildSettings),
+		history:  make(map[string]*trackHistory),
 	}
 }
 
@@ -29,58 +37,279 @@ type Player struct {
 	vcID    string
 	vc      *discordgo.VoiceConnection
 
-	track       *musicapi.SongDetail
-	requestedBy string
-
+	ctx    context.Context // player lifetime; bounds prefetched ffmpeg processes
 	cancel context.CancelFunc
 
-	mu     sync.Mutex
-	paused bool
-	cond   *sync.Cond
+	mu      sync.Mutex
+	current *QueueItem
+	queue   []*QueueItem
+	skip    context.CancelFunc
+	paused  bool
+	cond    *sync.Cond
+
+	// Seek bookkeeping: the current ffmpeg stream started at offset and has
+	// sent frames 20ms frames since. A seek stores seekTo and restarts it.
+	offset  time.Duration
+	seekTo  *time.Duration
+	restart context.CancelFunc
+	frames  atomic.Int64
+
+	volume atomic.Int32 // percent, read by the frame loop
+
+	prefetch     *prefetched   // next track, already decoding
+	queueChanged chan struct{} // wakes prefetchLoop; see queueEdited
+	closed       bool          // run has exited; no more prefetches
+
+	nowPlaying  *nowPlayingMsg // player message kept live for radio metadata
+	metaChanged chan struct{}  // wakes watchNowPlaying; see nowPlayingChanged
+
+	enc *gopus.Encoder // shared across tracks so the Opus stream is continuous
 }
 
-func (pm *PlaybackManager) Start(guildID, vcID, url string, track *musicapi.SongDetail, requestedBy string) error {
+// Enqueue adds item to the end of the guild queue, joining vcID and starting
+// playback if nothing is playing yet. The returned position is 0 when the
+// track starts right away, otherwise its 1-based place in the queue.
+func (pm *PlaybackManager) Enqueue(guildID, vcID string, item *QueueItem) (int, error) {
 	pm.mu.Lock()
-	// stop existing
 	if p := pm.players[guildID]; p != nil {
-		p.stop()
-		delete(pm.players, guildID)
+		p.mu.Lock()
+		pos := p.push(item)
+		p.queueEdited()
+		p.mu.Unlock()
+		pm.mu.Unlock()
+		return pos, nil
 	}
 	pm.mu.Unlock()
 
 	vc, err := pm.bot.dg.ChannelVoiceJoin(guildID, vcID, false, true)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	// Someone else may have started a player while we were joining.
+	if p := pm.players[guildID]; p != nil {
+		p.mu.Lock()
+		pos := p.push(item)
+		p.queueEdited()
+		p.mu.Unlock()
+		return pos, nil
+	}
+
+	ctx, cancel := context.WithCancel(pm.bot.ctx)
 	p := &Player{
-		guildID:     guildID,
-		vcID:        vcID,
-		vc:          vc,
-		cancel:      cancel,
-		track:       track,
-		requestedBy: requestedBy,
+		guildID: guildID,
+		vcID:    vcID,
+		vc:      vc,
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   []*QueueItem{item},
+
+		queueChanged: make(chan struct{}, 1),
+		metaChanged:  make(chan struct{}, 1),
 	}
 	p.cond = sync.NewCond(&p.mu)
-
-	pm.mu.Lock()
+	p.volume.Store(int32(pm.settingsLocked(guildID).volume))
 	pm.players[guildID] = p
-	pm.mu.Unlock()
 
-	// playback goroutine
-	go func() {
-		_ = pm.bot.playURLWithPause(ctx, p, url)
-		_ = vc.Disconnect()
+	go pm.run(ctx, p)
+	go pm.watchNowPlaying(ctx, p)
+
+	return 0, nil
+}
+
+// run plays queued tracks until the queue is empty or the player is stopped.
+func (pm *PlaybackManager) run(ctx context.Context, p *Player) {
+	defer func() {
+		p.dropPrefetch()
+		_ = p.vc.Disconnect()
 
 		pm.mu.Lock()
-		if pm.players[guildID] == p {
-			delete(pm.players, guildID)
+		if pm.players[p.guildID] == p {
+			delete(pm.players, p.guildID)
 		}
 		pm.mu.Unlock()
 	}()
 
-	return nil
+	enc, err := gopus.NewEncoder(sampleRate, channels, gopus.Audio)
+	if err != nil {
+		log.Printf("playback %s: opus encoder: %v", p.guildID, err)
+		return
+	}
+	p.enc = enc
+
+	// Give discord voice connection a moment to be ready
+	time.Sleep(300 * time.Millisecond)
+
+	// Stay "speaking" for the whole session so transitions are gapless.
+	_ = p.vc.Speaking(true)
+	defer func() { _ = p.vc.Speaking(false) }()
+
+	var last *QueueItem
+	for {
+		if pm.queueEmpty(p) && pm.Autoplay(p.guildID) {
+			if next := pm.autoplay(ctx, p.guildID, last); next != nil {
+				p.mu.Lock()
+				// a user may have queued something while we searched
+				if len(p.queue) == 0 {
+					p.push(next)
+				}
+				p.mu.Unlock()
+			}
+		}
+
+		item := pm.advance(p)
+		if item == nil {
+			return
+		}
+		last = item
+		if err := pm.resolve(ctx, p, item); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("playback %s: %v", p.guildID, err)
+			continue
+		}
+		pm.remember(p.guildID, item.Track)
+
+		trackCtx, skip := context.WithCancel(ctx)
+		p.mu.Lock()
+		p.skip = skip
+		p.mu.Unlock()
+
+		err := pm.playTrack(trackCtx, p, item)
+		skipped := trackCtx.Err() != nil
+		skip()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && !skipped {
+			log.Printf("playback %s: %v", p.guildID, err)
+			continue
+		}
+		pm.requeue(p, item, skipped)
+	}
+}
+
+// requeue applies the guild loop mode to a track that just ended. Track
+// loop only replays on a natural end so /skip still moves on; queue loop
+// keeps skipped tracks in the rotation.
+func (pm *PlaybackManager) requeue(p *Player, item *QueueItem, skipped bool) {
+	mode := pm.Loop(p.guildID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case mode == LoopTrack && !skipped:
+		p.queue = append([]*QueueItem{item}, p.queue...)
+	case mode == LoopQueue:
+		p.push(item)
+	}
+}
+
+// playTrack streams one track, restarting ffmpeg at the new offset whenever
+// a seek interrupts it. The voice connection stays up across restarts.
+func (pm *PlaybackManager) playTrack(ctx context.Context, p *Player, item *QueueItem) error {
+	// Use the source warmed up while the previous track played, if any.
+	src, offset := p.takePrefetched(item)
+	go pm.prefetchLoop(ctx, p, item)
+	drops := 0 // live streams only: consecutive early disconnects
+
+	for {
+		streamCtx, restart := context.WithCancel(ctx)
+		p.mu.Lock()
+		p.offset = offset
+		p.restart = restart
+		p.frames.Store(0)
+		p.mu.Unlock()
+
+		var err error
+		started := time.Now()
+		if src == nil && item.Live {
+			p.nowPlayingChanged() // post or redraw its player message
+			src, err = pm.openLive(streamCtx, p, item)
+		} else if src == nil {
+			src, err = pm.openWithFallback(streamCtx, p, item, offset)
+		}
+		if err == nil {
+			if n := int(pm.Crossfade(p.guildID) / frameDuration); n > 0 && !item.Live {
+				src = newCrossfadeSource(src, n, func() *prefetched { return pm.crossfadePartner(p) })
+			}
+			err = pm.bot.playSource(streamCtx, p, src)
+		}
+		src = nil
+		restart()
+		if ctx.Err() != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		seekTo := p.seekTo
+		p.seekTo = nil
+		p.mu.Unlock()
+		if seekTo == nil {
+			if item.Live && pm.reconnectLive(ctx, p, &drops, started, err) {
+				continue
+			}
+			return err
+		}
+		offset = *seekTo
+	}
+}
+
+// openWithFallback opens the item's stream, stepping down through the
+// track's other qualities while ffmpeg can't get audio out of one. The URL
+// that worked is kept on the item so seeks and loops reuse it. No audio
+// after a seek means the offset is at or past the end, so the track simply
+// ends there.
+func (pm *PlaybackManager) openWithFallback(ctx context.Context, p *Player, item *QueueItem, offset time.Duration) (AudioSource, error) {
+	p.mu.Lock()
+	url, track := item.URL, item.Track
+	p.mu.Unlock()
+
+	for {
+		src, err := pm.bot.openSource(ctx, url, offset)
+		if errors.Is(err, errNoAudio) && offset > 0 {
+			return newPCMSource(nil, 0), nil
+		}
+		if !errors.Is(err, errNoAudio) || track == nil {
+			return src, err
+		}
+		next := pm.bot.fallbackURL(track, url)
+		if next == "" {
+			return nil, err
+		}
+		log.Printf("playback %s: %v, trying next quality", p.guildID, err)
+		url = next
+
+		p.mu.Lock()
+		item.URL = url
+		p.mu.Unlock()
+	}
+}
+
+func (pm *PlaybackManager) queueEmpty(p *Player) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue) == 0
+}
+
+// advance moves the next queued track into current. When the queue is empty
+// the player is retired under pm.mu so Enqueue never pushes onto a dead player.
+func (pm *PlaybackManager) advance(p *Player) *QueueItem {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current = p.pop()
+	if p.current == nil && pm.players[p.guildID] == p {
+		delete(pm.players, p.guildID)
+	}
+	return p.current
 }
 
 func (pm *PlaybackManager) Pause(guildID string) {
@@ -106,6 +335,75 @@ func (pm *PlaybackManager) Stop(guildID string) {
 	}
 }
 
+// Skip ends the current track; the player moves on to the next queued one.
+func (pm *PlaybackManager) Skip(guildID string) (*QueueItem, bool) {
+	p := pm.get(guildID)
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	skipped := p.current.copy()
+	skip := p.skip
+	p.paused = false
+	p.mu.Unlock()
+
+	if skip != nil {
+		skip()
+	}
+	p.cond.Broadcast()
+	return skipped, skipped != nil
+}
+
+// Seek jumps the current track to pos, clamped to its length when known.
+// Live streams can't seek.
+func (pm *PlaybackManager) Seek(guildID string, pos time.Duration) bool {
+	p := pm.get(guildID)
+	if p == nil {
+		return false
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	p.mu.Lock()
+	if p.current == nil || p.restart == nil || p.current.Live {
+		p.mu.Unlock()
+		return false
+	}
+	if t := p.current.Track; t != nil && t.Duration > 0 && pos > t.Duration {
+		pos = t.Duration
+	}
+	p.seekTo = &pos
+	restart := p.restart
+	p.mu.Unlock()
+
+	restart()
+	p.mu.Lock()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	return true
+}
+
+// SeekBy moves the current track forwards (or backwards for negative delta).
+func (pm *PlaybackManager) SeekBy(guildID string, delta time.Duration) bool {
+	return pm.Seek(guildID, pm.Position(guildID)+delta)
+}
+
+// Position reports how far into the current track playback is. A pending
+// seek counts as already applied so the UI doesn't jump back and forth.
+func (pm *PlaybackManager) Position(guildID string) time.Duration {
+	p := pm.get(guildID)
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.seekTo != nil {
+		return *p.seekTo
+	}
+	return p.offset + time.Duration(p.frames.Load())*frameDuration
+}
+
 func (pm *PlaybackManager) Leave(guildID string) {
 	if p := pm.get(guildID); p != nil && p.vc != nil {
 		_ = p.vc.Disconnect()
@@ -137,11 +435,66 @@ func (pm *PlaybackManager) TrackInfo(guildID string) (track *musicapi.SongDetail
 	if p := pm.get(guildID); p != nil {
 		p.mu.Lock()
 		defer p.mu.Unlock()
-		return p.track, p.requestedBy, p.vcID, p.track != nil
+		if p.current == nil {
+			return nil, "", p.vcID, false
+		}
+		return p.current.Track, p.current.RequestedBy, p.vcID, true
 	}
 	return nil, "", "", false
 }
 
+// Queue returns the current track and a copy of the upcoming ones.
+func (pm *PlaybackManager) Queue(guildID string) (current *QueueItem, upcoming []*QueueItem) {
+	if p := pm.get(guildID); p != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.current.copy(), p.snapshot()
+	}
+	return nil, nil
+}
+
+func (pm *PlaybackManager) Remove(guildID string, pos int) (*QueueItem, error) {
+	p := pm.get(guildID)
+	if p == nil {
+		return nil, errBadPosition
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	item, err := p.removeAt(pos)
+	if err == nil {
+		p.queueEdited()
+	}
+	return item, err
+}
+
+func (pm *PlaybackManager) Move(guildID string, from, to int) error {
+	p := pm.get(guildID)
+	if p == nil {
+		return errBadPosition
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.moveTo(from, to); err != nil {
+		return err
+	}
+	p.queueEdited()
+	return nil
+}
+
+// Clear drops every upcoming track but keeps the current one playing.
+func (pm *PlaybackManager) Clear(guildID string) int {
+	p := pm.get(guildID)
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.queue)
+	p.queue = nil
+	p.queueEdited()
+	return n
+}
+
 func (pm *PlaybackManager) get(guildID string) *Player {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
@@ -152,6 +505,18 @@ func (p *Player) stop() {
 	if p.cancel != nil {
 		p.cancel()
 	}
+	// wake a paused playback loop so it notices the cancellation
+	p.mu.Lock()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// dropping reports whether a paused player should keep reading and discard
+// audio: a paused live stream stays at the live point instead of buffering.
+func (p *Player) dropping() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused && p.current != nil && p.current.Live
 }
 
 func (p *Player) waitIfPaused(ctx context.Context) error {
@@ -0,0 +1,256 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// prefetchFrames is how much decoded audio (in 20ms frames) a prefetched
+	// track buffers ahead of time; 250 frames is 5s.
+	prefetchFrames = 250
+	// prefetchLead is how long before the current track ends (or its
+	// crossfade begins) the next one is resolved and started.
+	prefetchLead = 15 * time.Second
+	// prefetchPoll is how often the playback position is checked against
+	// the lead.
+	prefetchPoll = time.Second
+)
+
+// prefetched is the warmed-up source for the next queued track.
+type prefetched struct {
+	item *QueueItem
+	src  *bufferedSource
+	lead int // frames already played while crossfading into it
+}
+
+// resolve fills in the stream URL (and full details) of an item that was
+// queued with only search-level metadata.
+func (pm *PlaybackManager) resolve(ctx context.Context, p *Player, item *QueueItem) error {
+	p.mu.Lock()
+	id, url := item.Track.ID, item.URL
+	p.mu.Unlock()
+	if url != "" {
+		return nil
+	}
+
+	detail, err := pm.bot.music.Resolve(ctx, id)
+	if err != nil {
+		return err
+	}
+	stream := pm.bot.playableURL(detail)
+	if stream == "" {
+		return errors.New("no playable audio URL for " + id)
+	}
+
+	p.mu.Lock()
+	item.Track = detail
+	item.URL = stream
+	p.mu.Unlock()
+	return nil
+}
+
+// prefetchLoop runs alongside item's playback and prefetches the next
+// queued track once item is within prefetchLead of its end. Queue edits
+// wake it so a new head of the queue is picked up (and a stale prefetch
+// released) without waiting for the next poll.
+func (pm *PlaybackManager) prefetchLoop(ctx context.Context, p *Player, item *QueueItem) {
+	tick := time.NewTicker(prefetchPoll)
+	defer tick.Stop()
+
+	var failed *QueueItem // don't retry a track that wouldn't resolve or open
+	for {
+		if pm.nearEnd(p, item) {
+			failed = pm.prefetchNext(ctx, p, failed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		case <-p.queueChanged:
+		}
+	}
+}
+
+// nearEnd reports whether item, the current track, is close enough to its
+// end to prefetch what follows. Tracks of unknown length prefetch right
+// away; live streams never end.
+func (pm *PlaybackManager) nearEnd(p *Player, item *QueueItem) bool {
+	// A crossfade starts mixing in the next track that much earlier.
+	lead := prefetchLead + pm.Crossfade(p.guildID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if item.Live {
+		return false
+	}
+	if item.Track == nil || item.Track.Duration <= 0 {
+		return true
+	}
+	pos := p.offset + time.Duration(p.frames.Load())*frameDuration
+	return item.Track.Duration-pos <= lead
+}
+
+// prefetchNext resolves the track after the current one and starts decoding
+// it into a buffer so the transition doesn't wait on the API or a cold ffmpeg.
+// A prefetch for a track that is no longer next is released. It returns the
+// item that failed to prefetch, or nil; skip is not attempted again.
+func (pm *PlaybackManager) prefetchNext(ctx context.Context, p *Player, skip *QueueItem) *QueueItem {
+	p.mu.Lock()
+	// Once ctx is done the player may have moved on, and what looks stale
+	// here is the prefetch the new track is about to take.
+	if ctx.Err() != nil {
+		p.mu.Unlock()
+		return skip
+	}
+	var next *QueueItem
+	if len(p.queue) > 0 {
+		next = p.queue[0]
+	}
+	var stale *prefetched
+	if p.prefetch != nil && p.prefetch.item != next {
+		stale, p.prefetch = p.prefetch, nil
+	}
+	already := p.prefetch != nil
+	live := next != nil && next.Live // holding a radio connection open would only fall behind
+	p.mu.Unlock()
+	if stale != nil {
+		_ = stale.src.Close()
+	}
+	if next == nil || already || live || next == skip {
+		return skip
+	}
+
+	if err := pm.resolve(ctx, p, next); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("prefetch %s: %v", p.guildID, err)
+		}
+		return next
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	url := next.URL
+	p.mu.Unlock()
+	// The source outlives the current track, so its ffmpeg is tied to the
+	// player rather than ctx; a skip mid-start is caught below.
+	src, err := pm.bot.openSource(p.ctx, url, 0)
+	if err != nil {
+		log.Printf("prefetch %s: %v", p.guildID, err)
+		return next
+	}
+	buf := newBufferedSource(src, prefetchFrames)
+
+	// The track may have been skipped, or the player stopped (and its
+	// prefetch dropped), while ffmpeg was starting.
+	p.mu.Lock()
+	if ctx.Err() != nil || p.closed {
+		p.mu.Unlock()
+		_ = buf.Close()
+		return nil
+	}
+	old := p.prefetch
+	p.prefetch = &prefetched{item: next, src: buf}
+	p.mu.Unlock()
+	if old != nil {
+		_ = old.src.Close()
+	}
+	return nil
+}
+
+// takePrefetched hands over the buffered source for item, if it is the one
+// that was prefetched, along with how much of it a crossfade already played.
+// A stale prefetch (queue changed since) is discarded.
+func (p *Player) takePrefetched(item *QueueItem) (AudioSource, time.Duration) {
+	p.mu.Lock()
+	pf := p.prefetch
+	p.prefetch = nil
+	p.mu.Unlock()
+
+	if pf == nil {
+		return nil, 0
+	}
+	if pf.item != item {
+		_ = pf.src.Close()
+		return nil, 0
+	}
+	return pf.src, time.Duration(pf.lead) * frameDuration
+}
+
+// dropPrefetch releases any prefetched source when the player shuts down
+// and stops later prefetches from being stored.
+func (p *Player) dropPrefetch() {
+	p.mu.Lock()
+	p.closed = true
+	pf := p.prefetch
+	p.prefetch = nil
+	p.mu.Unlock()
+	if pf != nil {
+		_ = pf.src.Close()
+	}
+}
+
+// queueEdited wakes the prefetch loop after a change that may have
+// replaced the head of the queue. Callers may hold p.mu.
+func (p *Player) queueEdited() {
+	select {
+	case p.queueChanged <- struct{}{}:
+	default:
+	}
+}
+
+// bufferedSource decodes its underlying source ahead of the reader.
+type bufferedSource struct {
+	src    AudioSource
+	frames chan []int16
+	err    error // why frames was closed; valid once it is
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newBufferedSource(src AudioSource, depth int) *bufferedSource {
+	b := &bufferedSource{
+		src:    src,
+		frames: make(chan []int16, depth),
+		done:   make(chan struct{}),
+	}
+	go b.fill()
+	return b
+}
+
+func (b *bufferedSource) fill() {
+	defer close(b.frames)
+	for {
+		f := make([]int16, frameSize*channels)
+		if err := b.src.ReadFrame(f); err != nil {
+			b.err = err
+			return
+		}
+		select {
+		case b.frames <- f:
+		case <-b.done:
+			b.err = io.EOF
+			return
+		}
+	}
+}
+
+func (b *bufferedSource) ReadFrame(dst []int16) error {
+	f, ok := <-b.frames
+	if !ok {
+		return b.err
+	}
+	copy(dst, f)
+	return nil
+}
+
+func (b *bufferedSource) Close() error {
+	b.once.Do(func() { close(b.done) })
+	return b.src.Close()
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"musicbot/internal/musicapi"
+)
+
+// probeTimeout bounds ffprobe on slow or endless streams.
+const probeTimeout = 15 * time.Second
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType string            `json:"codec_type"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"streams"`
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+// probeURL builds track details for a direct audio link from ffprobe. It
+// fails when the link has no audio stream or points at a non-public
+// address.
+func (b *Bot) probeURL(ctx context.Context, link string) (*musicapi.SongDetail, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	if err := checkPublicURL(ctx, link); err != nil {
+		return nil, err
+	}
+	out, err := exec.CommandContext(ctx, b.cfg.FFprobePath,
+		"-v", "error",
+		"-protocol_whitelist", webProtocols,
+		"-show_entries", "format=duration:format_tags:stream=codec_type:stream_tags",
+		"-of", "json",
+		link,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+	var probe probeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("ffprobe output: %w", err)
+	}
+
+	hasAudio := false
+	tags := make(map[string]string)
+	for _, st := range probe.Streams {
+		if st.CodecType == "audio" {
+			hasAudio = true
+			mergeTags(tags, st.Tags)
+		}
+	}
+	if !hasAudio {
+		return nil, fmt.Errorf("no audio stream in %s", link)
+	}
+	mergeTags(tags, probe.Format.Tags) // container tags win
+
+	title := musicapi.CleanText(firstTag(tags, "title", "icy-name"))
+	if title == "" {
+		title = titleFromURL(link)
+	}
+	artists := musicapi.SplitArtists(musicapi.CleanText(firstTag(tags, "artist", "album_artist")))
+	d := &musicapi.SongDetail{
+		SongLite: musicapi.SongLite{
+			ID:        link,
+			Title:     title,
+			Artist:    musicapi.JoinArtists(artists),
+			Artists:   artists,
+			Link:      link,
+			StreamURL: link,
+		},
+		Album: musicapi.CleanText(firstTag(tags, "album")),
+	}
+	if secs, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil && secs > 0 {
+		d.Duration = time.Duration(secs * float64(time.Second))
+	}
+	if y := firstTag(tags, "date", "year"); len(y) >= 4 {
+		d.Year, _ = strconv.Atoi(y[:4])
+	}
+	return d, nil
+}
+
+// mergeTags copies src into dst with lowercased keys (ffprobe keeps the
+// container's spelling: "TITLE", "title", ...).
+func mergeTags(dst, src map[string]string) {
+	for k, v := range src {
+		if v = strings.TrimSpace(v); v != "" {
+			dst[strings.ToLower(k)] = v
+		}
+	}
+}
+
+func firstTag(tags map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := tags[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// titleFromURL names a track after the last path segment of its link.
+func titleFromURL(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return u.Host
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	if ext := path.Ext(name); ext != "" && len(ext) <= 5 {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return musicapi.CleanText(strings.ReplaceAll(name, "_", " "))
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"errors"
+
+	"musicbot/internal/musicapi"
+)
+
+// QueueItem is one track waiting in (or playing from) a guild queue.
+// URL may be empty for items queued from search results; the player
+// resolves them through the API before (or while prefetching) playback.
+type QueueItem struct {
+	Track       *musicapi.SongDetail
+	URL         string
+	RequestedBy string
+	Live        bool // endless radio stream: no seeking, prefetch or crossfade
+
+	// announceIn is the channel a station queued behind other tracks posts
+	// its player message to when it starts. Guarded by the player's mu.
+	announceIn string
+}
+
+var errBadPosition = errors.New("no track at that position")
+
+// Queue operations below work on 1-based positions of the upcoming tracks
+// (the currently playing track is not part of p.queue).
+// Callers must hold p.mu.
+
+func (p *Player) push(item *QueueItem) int {
+	p.queue = append(p.queue, item)
+	return len(p.queue)
+}
+
+func (p *Player) pop() *QueueItem {
+	if len(p.queue) == 0 {
+		return nil
+	}
+	item := p.queue[0]
+	p.queue[0] = nil
+	p.queue = p.queue[1:]
+	return item
+}
+
+func (p *Player) removeAt(pos int) (*QueueItem, error) {
+	if pos < 1 || pos > len(p.queue) {
+		return nil, errBadPosition
+	}
+	item := p.queue[pos-1]
+	p.queue = append(p.queue[:pos-1], p.queue[pos:]...)
+	return item, nil
+}
+
+func (p *Player) moveTo(from, to int) error {
+	if from < 1 || from > len(p.queue) || to < 1 || to > len(p.queue) {
+		return errBadPosition
+	}
+	item := p.queue[from-1]
+	p.queue = append(p.queue[:from-1], p.queue[from:]...)
+	p.queue = append(p.queue[:to-1], append([]*QueueItem{item}, p.queue[to-1:]...)...)
+	return nil
+}
+
+// snapshot copies the upcoming items so callers can read them without p.mu
+// while the player resolves them in the background.
+func (p *Player) snapshot() []*QueueItem {
+	out := make([]*QueueItem, len(p.queue))
+	for n, item := range p.queue {
+		out[n] = item.copy()
+	}
+	return out
+}
+
+func (item *QueueItem) copy() *QueueItem {
+	if item == nil {
+		return nil
+	}
+	cp := *item
+	return &cp
+}
@@ -0,0 +1,288 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// A live stream that drops is reconnected, backing off by
+	// liveReconnectDelay per attempt. After liveMaxDrops drops in a row,
+	// each within liveStableAfter of connecting, the station is given up.
+	liveReconnectDelay = 2 * time.Second
+	liveMaxDrops       = 3
+	liveStableAfter    = 30 * time.Second
+
+	maxRadioPresets = 25 // per guild; also Discord's choice list limit
+)
+
+// messageRef points at a channel message the bot keeps editing.
+type messageRef struct {
+	ChannelID string
+	MessageID string
+}
+
+// nowPlayingMsg is the player message showing one queue item.
+type nowPlayingMsg struct {
+	item *QueueItem
+	ref  messageRef
+}
+
+// openLive connects item's radio stream, feeding metadata changes back
+// into the queue item. The player message is redrawn by watchNowPlaying,
+// never from the stream's goroutine: a slow or rate-limited edit must not
+// hold up the audio on its way to ffmpeg.
+func (pm *PlaybackManager) openLive(ctx context.Context, p *Player, item *QueueItem) (AudioSource, error) {
+	return pm.bot.openLiveSource(ctx, item.URL, func(meta liveMeta) {
+		p.mu.Lock()
+		item.Track = applyLiveMeta(item.Track, meta)
+		p.mu.Unlock()
+		p.nowPlayingChanged()
+	})
+}
+
+// nowPlayingChanged wakes watchNowPlaying. Signals that arrive while it is
+// busy collapse into one redraw.
+func (p *Player) nowPlayingChanged() {
+	select {
+	case p.metaChanged <- struct{}{}:
+	default:
+	}
+}
+
+// watchNowPlaying keeps the player message of a live item up to date for
+// as long as the player runs. A station that was queued behind other
+// tracks (see AnnounceIn) gets its player message posted here, once it
+// starts.
+func (pm *PlaybackManager) watchNowPlaying(ctx context.Context, p *Player) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.metaChanged:
+		}
+
+		p.mu.Lock()
+		item, np := p.current, p.nowPlaying
+		announce := ""
+		if item != nil && item.Live && (np == nil || np.item != item) {
+			announce = item.announceIn
+		}
+		p.mu.Unlock()
+
+		switch {
+		case item == nil || !item.Live:
+		case np != nil && np.item == item:
+			pm.bot.refreshNowPlaying(p.guildID, np.ref)
+		case announce != "":
+			msg, err := pm.bot.postLivePlayer(announce, p.guildID, item)
+			if err != nil {
+				log.Printf("radio %s: post player message: %v", p.guildID, err)
+				continue
+			}
+			pm.SetNowPlaying(p.guildID, item, messageRef{ChannelID: msg.ChannelID, MessageID: msg.ID})
+		}
+	}
+}
+
+// reconnectLive decides whether a live stream that ended (err may be nil:
+// servers do close "endless" streams) should be reopened, waiting first.
+func (pm *PlaybackManager) reconnectLive(ctx context.Context, p *Player, drops *int, started time.Time, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if time.Since(started) > liveStableAfter {
+		*drops = 0
+	}
+	*drops++
+	if *drops > liveMaxDrops {
+		log.Printf("radio %s: giving up after %d drops: %v", p.guildID, liveMaxDrops, err)
+		return false
+	}
+	log.Printf("radio %s: stream ended (%v), reconnecting", p.guildID, err)
+	select {
+	case <-time.After(time.Duration(*drops) * liveReconnectDelay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// SetNowPlaying registers the message showing item so radio metadata
+// updates can edit it.
+func (pm *PlaybackManager) SetNowPlaying(guildID string, item *QueueItem, ref messageRef) {
+	if p := pm.get(guildID); p != nil {
+		p.mu.Lock()
+		p.nowPlaying = &nowPlayingMsg{item: item, ref: ref}
+		p.mu.Unlock()
+	}
+}
+
+// AnnounceIn has a queued live item post its player message to channelID
+// once it starts playing.
+func (pm *PlaybackManager) AnnounceIn(guildID string, item *QueueItem, channelID string) {
+	if p := pm.get(guildID); p != nil {
+		p.mu.Lock()
+		item.announceIn = channelID
+		p.mu.Unlock()
+		p.nowPlayingChanged() // in case it already started
+	}
+}
+
+// IsLive reports whether the guild is playing a live stream.
+func (pm *PlaybackManager) IsLive(guildID string) bool {
+	if p := pm.get(guildID); p != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.current != nil && p.current.Live
+	}
+	return false
+}
+
+// postLivePlayer sends the player for a live item as a regular channel
+// message, which (unlike interaction replies) stays editable for as long
+// as the station plays.
+func (b *Bot) postLivePlayer(channelID, guildID string, item *QueueItem) (*discordgo.Message, error) {
+	track, requestedBy, vcID, ok := b.pm.TrackInfo(guildID)
+	if !ok || track == nil {
+		// the player may not have picked the item up yet
+		track, requestedBy = item.Track, item.RequestedBy
+	}
+	ui := b.uiState(guildID, track, "Live", vcID, requestedBy)
+	ui.Live = true
+	return b.dg.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{NowPlayingEmbed(track, ui)},
+		Components: PlayerControls(false, b.pm.Loop(guildID)),
+	})
+}
+
+// refreshNowPlaying redraws the player message with the current track.
+func (b *Bot) refreshNowPlaying(guildID string, ref messageRef) {
+	track, requestedBy, vcID, ok := b.pm.TrackInfo(guildID)
+	if !ok || track == nil {
+		return
+	}
+	status := "Live"
+	if b.pm.IsPaused(guildID) {
+		status = "Paused"
+	}
+	embed := NowPlayingEmbed(track, b.uiState(guildID, track, status, vcID, requestedBy))
+	_, err := b.dg.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel: ref.ChannelID,
+		ID:      ref.MessageID,
+		Embeds:  &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("radio %s: update player message: %v", guildID, err)
+	}
+}
+
+// --- presets ---
+
+// radioPresets stores named stations per guild in a JSON file.
+type radioPresets struct {
+	path string
+
+	mu     sync.Mutex
+	guilds map[string]map[string]string // guildID -> name -> URL
+}
+
+type radioPreset struct {
+	Name string
+	URL  string
+}
+
+var errTooManyPresets = errors.New("too many presets")
+
+func loadRadioPresets(path string) (*radioPresets, error) {
+	rp := &radioPresets{path: path, guilds: make(map[string]map[string]string)}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &rp.guilds); err != nil {
+		return nil, err
+	}
+	return rp, nil
+}
+
+func presetKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (rp *radioPresets) Get(guildID, name string) (string, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	u, ok := rp.guilds[guildID][presetKey(name)]
+	return u, ok
+}
+
+func (rp *radioPresets) Set(guildID, name, url string) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	g := rp.guilds[guildID]
+	if g == nil {
+		g = make(map[string]string)
+		rp.guilds[guildID] = g
+	}
+	key := presetKey(name)
+	if _, exists := g[key]; !exists && len(g) >= maxRadioPresets {
+		return errTooManyPresets
+	}
+	g[key] = url
+	return rp.saveLocked()
+}
+
+func (rp *radioPresets) Delete(guildID, name string) (bool, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	key := presetKey(name)
+	if _, ok := rp.guilds[guildID][key]; !ok {
+		return false, nil
+	}
+	delete(rp.guilds[guildID], key)
+	if len(rp.guilds[guildID]) == 0 {
+		delete(rp.guilds, guildID)
+	}
+	return true, rp.saveLocked()
+}
+
+func (rp *radioPresets) List(guildID string) []radioPreset {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	out := make([]radioPreset, 0, len(rp.guilds[guildID]))
+	for name, u := range rp.guilds[guildID] {
+		out = append(out, radioPreset{Name: name, URL: u})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// saveLocked writes the file atomically; callers hold rp.mu.
+func (rp *radioPresets) saveLocked() error {
+	raw, err := json.MarshalIndent(rp.guilds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(rp.path), 0o755); err != nil {
+		return err
+	}
+	tmp := rp.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rp.path)
+}
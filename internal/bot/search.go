@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"musicbot/internal/cache"
+	"musicbot/internal/musicapi"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	searchPageSize = 25 // Discord's limit on select menu options
+	// searchFetchSize is how many results are asked of paging providers
+	// at a time, enough for two pages of the menu.
+	searchFetchSize = 50
+
+	searchSessionCount = 256
+	// searchSessionTTL matches the lifetime of the /play interaction
+	// token; older result messages can't be edited by it anyway.
+	searchSessionTTL = 15 * time.Minute
+
+	searchPrevPrefix = "search_prev:"
+	searchNextPrefix = "search_next:"
+)
+
+// searchSession holds the results behind one /play search message so its
+// Previous/Next buttons can page through them. Sessions are keyed by the
+// ID of the interaction that started the search.
+type searchSession struct {
+	mu        sync.Mutex
+	query     string
+	results   []musicapi.SongLite
+	seen      map[string]bool // IDs and SongKeys already in results
+	fetched   int             // provider pages loaded so far
+	exhausted bool            // the provider has nothing more to give
+	page      int             // 0-based page currently shown
+}
+
+func newSearchSessions() *cache.LRU[string, *searchSession] {
+	return cache.New[string, *searchSession](searchSessionCount, searchSessionTTL)
+}
+
+// fill loads results until page n is full or the provider runs out.
+// Providers without paging are searched once.
+func (ss *searchSession) fill(ctx context.Context, provider musicapi.Provider, n int) error {
+	need := (n + 1) * searchPageSize
+	for len(ss.results) < need && !ss.exhausted {
+		pp, paged := provider.(musicapi.PagedProvider)
+		var batch []musicapi.SongLite
+		var err error
+		if paged {
+			batch, err = pp.SearchPage(ctx, ss.query, ss.fetched+1, searchFetchSize)
+		} else {
+			batch, err = provider.Search(ctx, ss.query)
+		}
+		if err != nil {
+			return err
+		}
+		ss.fetched++
+		if !paged {
+			ss.exhausted = true
+		}
+
+		added := 0
+		for _, song := range musicapi.RankSongs(ss.query, batch) {
+			key := musicapi.SongKey(song)
+			if ss.seen[song.ID] || ss.seen[key] {
+				continue
+			}
+			ss.seen[song.ID] = true
+			ss.seen[key] = true
+			ss.results = append(ss.results, song)
+			added++
+		}
+		// APIs that ignore page keep returning the first one
+		if added == 0 {
+			ss.exhausted = true
+		}
+	}
+	return nil
+}
+
+func (ss *searchSession) pageCount() int {
+	return (len(ss.results) + searchPageSize - 1) / searchPageSize
+}
+
+// message renders the current page as an embed with the track menu and,
+// when there is more than one page, Previous/Next buttons.
+func (ss *searchSession) message(id string) ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	start := ss.page * searchPageSize
+	end := min(start+searchPageSize, len(ss.results))
+
+	opts := make([]discordgo.SelectMenuOption, 0, end-start)
+	for _, song := range ss.results[start:end] {
+		opts = append(opts, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("%s — %s", song.Title, song.Artist), 100),
+			Description: truncate(song.Artist, 100),
+			Value:       song.ID,
+		})
+	}
+
+	pages := fmt.Sprint(ss.pageCount())
+	if !ss.exhausted {
+		pages += "+"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "Search Results",
+		Description: fmt.Sprintf("Query: **%s**\nSelect a track below.", musicapi.EscapeMarkdown(ss.query)),
+		Color:       uiColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%s • results %d–%d", ss.page+1, pages, start+1, end),
+		},
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    playSelectID,
+				Placeholder: "Pick a track to play…",
+				Options:     opts,
+			},
+		}},
+	}
+	hasNext := end < len(ss.results) || !ss.exhausted
+	if ss.page > 0 || hasNext {
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Previous",
+				Style:    discordgo.SecondaryButton,
+				CustomID: searchPrevPrefix + id,
+				Disabled: ss.page == 0,
+			},
+			discordgo.Button{
+				Label:    "Next",
+				Style:    discordgo.SecondaryButton,
+				CustomID: searchNextPrefix + id,
+				Disabled: !hasNext,
+			},
+		}})
+	}
+	return []*discordgo.MessageEmbed{embed}, components
+}
+
+// handleSearchPage moves a search results message one page back or forward.
+func (b *Bot) handleSearchPage(s *discordgo.Session, i *discordgo.InteractionCreate, id string, delta int) {
+	ss, ok := b.searches.Get(id)
+	if !ok {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{Title: "Search Results", Description: "These results expired. Run `/play` again.", Color: uiColor},
+				},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	// Loading the next provider page can outlast the 3s deadline.
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	ctx, cancel := b.interactionContext(i)
+	defer cancel()
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	page := max(ss.page+delta, 0)
+	if err := ss.fill(ctx, b.music, page); err != nil {
+		followupText(s, i, apiErrorText(err))
+		return
+	}
+	ss.page = min(page, max(ss.pageCount()-1, 0))
+
+	embeds, components := ss.message(id)
+	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+}
+
+// searchPageAction splits a Previous/Next button ID into its session ID and
+// direction.
+func searchPageAction(cid string) (id string, delta int, ok bool) {
+	if id, ok := strings.CutPrefix(cid, searchPrevPrefix); ok {
+		return id, -1, true
+	}
+	if id, ok := strings.CutPrefix(cid, searchNextPrefix); ok {
+		return id, 1, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVolume = 100
+	maxVolume     = 200
+)
+
+// guildSettings are per-guild playback preferences. They live on the
+// PlaybackManager so they carry over between players and tracks.
+type guildSettings struct {
+	volume    int // percent, 0-maxVolume
+	loop      LoopMode
+	autoplay  bool          // queue a related track when the queue runs out
+	crossfade time.Duration // overlap between tracks, 0 for gapless
+}
+
+// LoopMode decides what happens to a track once it finishes.
+type LoopMode int
+
+const (
+	LoopOff   LoopMode = iota
+	LoopTrack          // replay the same track
+	LoopQueue          // send the track to the back of the queue
+)
+
+func (m LoopMode) String() string {
+	switch m {
+	case LoopTrack:
+		return "track"
+	case LoopQueue:
+		return "queue"
+	default:
+		return "off"
+	}
+}
+
+// Next cycles off -> track -> queue -> off for the repeat button.
+func (m LoopMode) Next() LoopMode {
+	return (m + 1) % 3
+}
+
+func ParseLoopMode(s string) (LoopMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off", "none":
+		return LoopOff, nil
+	case "track", "song", "one":
+		return LoopTrack, nil
+	case "queue", "all":
+		return LoopQueue, nil
+	}
+	return LoopOff, fmt.Errorf("unknown loop mode %q (use track, queue or off)", s)
+}
+
+// settingsLocked returns the guild's settings, creating defaults on first use.
+// Callers must hold pm.mu.
+func (pm *PlaybackManager) settingsLocked(guildID string) *guildSettings {
+	gs := pm.settings[guildID]
+	if gs == nil {
+		gs = &guildSettings{volume: defaultVolume}
+		pm.settings[guildID] = gs
+	}
+	return gs
+}
+
+// SetVolume stores the guild volume and applies it to the playing track
+// from the next frame on.
+func (pm *PlaybackManager) SetVolume(guildID string, vol int) int {
+	vol = clampVolume(vol)
+
+	pm.mu.Lock()
+	pm.settingsLocked(guildID).volume = vol
+	p := pm.players[guildID]
+	pm.mu.Unlock()
+
+	if p != nil {
+		p.volume.Store(int32(vol))
+	}
+	return vol
+}
+
+// AdjustVolume changes the guild volume by delta percent.
+func (pm *PlaybackManager) AdjustVolume(guildID string, delta int) int {
+	return pm.SetVolume(guildID, pm.Volume(guildID)+delta)
+}
+
+func (pm *PlaybackManager) Volume(guildID string) int {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.settingsLocked(guildID).volume
+}
+
+func clampVolume(vol int) int {
+	if vol < 0 {
+		return 0
+	}
+	if vol > maxVolume {
+		return maxVolume
+	}
+	return vol
+}
+
+func (pm *PlaybackManager) SetLoop(guildID string, mode LoopMode) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.settingsLocked(guildID).loop = mode
+}
+
+// CycleLoop advances the guild loop mode and returns the new one.
+func (pm *PlaybackManager) CycleLoop(guildID string) LoopMode {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	gs := pm.settingsLocked(guildID)
+	gs.loop = gs.loop.Next()
+	return gs.loop
+}
+
+func (pm *PlaybackManager) Loop(guildID string) LoopMode {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.settingsLocked(guildID).loop
+}
+
+func (pm *PlaybackManager) SetAutoplay(guildID string, on bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.settingsLocked(guildID).autoplay = on
+}
+
+func (pm *PlaybackManager) Autoplay(guildID string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.settingsLocked(guildID).autoplay
+}
+
+// SetCrossfade stores the guild crossfade, clamped to 0-maxCrossfade.
+func (pm *PlaybackManager) SetCrossfade(guildID string, d time.Duration) time.Duration {
+	if d < 0 {
+		d = 0
+	}
+	if d > maxCrossfade {
+		d = maxCrossfade
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.settingsLocked(guildID).crossfade = d
+	return d
+}
+
+func (pm *PlaybackManager) Crossfade(guildID string) time.Duration {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.settingsLocked(guildID).crossfade
+}
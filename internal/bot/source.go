@@ -0,0 +1,316 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AudioSource yields 20ms frames of 48kHz stereo s16le PCM.
+type AudioSource interface {
+	// ReadFrame fills dst (frameSize*channels samples) with the next frame.
+	// It returns io.EOF once the source is exhausted.
+	ReadFrame(dst []int16) error
+	Close() error
+}
+
+const frameBytes = frameSize * channels * 2
+
+// errNoAudio means ffmpeg exited (or was killed after ffmpegFirstAudio)
+// before producing any audio, usually because the URL is dead or not audio.
+var errNoAudio = errors.New("ffmpeg produced no audio")
+
+// ffmpegFirstAudio is how long a fresh ffmpeg gets to produce its first
+// audio. Inputs that connect but never send data would otherwise hang the
+// caller; -reconnect makes those more likely.
+const ffmpegFirstAudio = 15 * time.Second
+
+// openSource picks the AudioSource for a track. Local WAV files that are
+// already 48kHz stereo 16-bit are read directly; everything else goes through ffmpeg,
+// which is killed when ctx is done.
+func (b *Bot) openSource(ctx context.Context, audioURL string, offset time.Duration) (AudioSource, error) {
+	if isLocalPath(audioURL) && strings.EqualFold(filepath.Ext(audioURL), ".wav") {
+		src, err := openWAVSource(audioURL, offset)
+		if err == nil {
+			return src, nil
+		}
+		if !errors.Is(err, errWAVFormat) {
+			return nil, err
+		}
+	}
+	return startFFmpegSource(ctx, b.cfg.FFmpegPath, audioURL, offset)
+}
+
+func isLocalPath(s string) bool {
+	return !strings.Contains(s, "://")
+}
+
+// webProtocols is the -protocol_whitelist for remote inputs: playlists
+// and other container formats can name further inputs, and those must not
+// reach file: or any other local protocol.
+const webProtocols = "http,https,tcp,tls"
+
+// --- ffmpeg ---
+
+type ffmpegSource struct {
+	cmd    *exec.Cmd
+	reader *bufio.Reader
+	buf    []byte
+}
+
+func startFFmpegSource(ctx context.Context, ffmpegPath, audioURL string, offset time.Duration) (*ffmpegSource, error) {
+	var args []string
+	if !isLocalPath(audioURL) {
+		args = append(args,
+			"-protocol_whitelist", webProtocols,
+			"-reconnect", "1",
+			"-reconnect_streamed", "1",
+			"-reconnect_delay_max", "5",
+		)
+	}
+	if offset > 0 {
+		// input seeking: ffmpeg jumps before decoding, so this is fast
+		args = append(args, "-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64))
+	}
+	args = append(args, "-i", audioURL)
+
+	src, err := startFFmpeg(ctx, ffmpegPath, args, nil)
+	if errors.Is(err, errNoAudio) {
+		return nil, fmt.Errorf("%w: %s", err, audioURL)
+	}
+	return src, err
+}
+
+// startFFmpeg runs ffmpeg with the given input arguments, decoding to raw
+// PCM s16le 48k stereo on stdout. stdin feeds "-i pipe:0" inputs. The
+// process lives until the source is closed or ctx is done.
+func startFFmpeg(ctx context.Context, ffmpegPath string, inputArgs []string, stdin io.Reader) (*ffmpegSource, error) {
+	args := append(inputArgs,
+		"-f", "s16le",
+		"-ar", strconv.Itoa(sampleRate),
+		"-ac", strconv.Itoa(channels),
+		"pipe:1",
+	)
+	ff := exec.CommandContext(ctx, ffmpegPath, args...)
+	ff.Stdin = stdin
+
+	stdout, err := ff.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, _ := ff.StderrPipe()
+
+	if err := ff.Start(); err != nil {
+		return nil, err
+	}
+
+	// Drain stderr so ffmpeg never blocks (important!)
+	go func() { _, _ = io.Copy(io.Discard, stderr) }()
+
+	src := &ffmpegSource{
+		cmd:    ff,
+		reader: bufio.NewReaderSize(stdout, 1<<20),
+		buf:    make([]byte, frameBytes),
+	}
+	// Wait for the first frame so an input ffmpeg can't open fails here,
+	// where the caller can still try another one. A clip shorter than one
+	// frame still counts as audio.
+	peeked := make(chan int, 1)
+	go func() {
+		b, _ := src.reader.Peek(frameBytes)
+		peeked <- len(b)
+	}()
+	timeout := time.NewTimer(ffmpegFirstAudio)
+	defer timeout.Stop()
+
+	select {
+	case n := <-peeked:
+		if n > 0 {
+			return src, nil
+		}
+		_ = src.Close()
+		return nil, errNoAudio
+	case <-timeout.C:
+		err = fmt.Errorf("%w within %s", errNoAudio, ffmpegFirstAudio)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	// killing ffmpeg closes stdout and ends the Peek
+	_ = ff.Process.Kill()
+	<-peeked
+	_ = src.Close()
+	return nil, err
+}
+
+func (s *ffmpegSource) ReadFrame(dst []int16) error {
+	return readInt16Frame(s.reader, s.buf, dst)
+}
+
+func (s *ffmpegSource) Close() error {
+	_ = s.cmd.Process.Kill()
+	_ = s.cmd.Wait()
+	return nil
+}
+
+// --- WAV ---
+
+var errWAVFormat = errors.New("wav is not 48kHz stereo 16-bit PCM")
+
+type wavSource struct {
+	f      *os.File
+	reader *bufio.Reader
+	buf    []byte
+}
+
+func openWAVSource(path string, offset time.Duration) (*wavSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dataStart, dataLen, err := parseWAVHeader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	skip := int64(offset/frameDuration) * frameBytes
+	if skip > dataLen {
+		skip = dataLen
+	}
+	if _, err := f.Seek(dataStart+skip, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &wavSource{
+		f:      f,
+		reader: bufio.NewReader(io.LimitReader(f, dataLen-skip)),
+		buf:    make([]byte, frameBytes),
+	}, nil
+}
+
+// parseWAVHeader walks the RIFF chunks and returns where the PCM data starts
+// and how long it is.
+func parseWAVHeader(r io.Reader) (dataStart, dataLen int64, err error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return 0, 0, fmt.Errorf("wav header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return 0, 0, errors.New("not a RIFF/WAVE file")
+	}
+
+	pos := int64(12)
+	sawFmt := false
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, 0, fmt.Errorf("wav chunks: %w", err)
+		}
+		pos += 8
+		id := string(hdr[0:4])
+		size := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+
+		switch id {
+		case "fmt ":
+			// only the first 16 bytes matter; the size comes from the file
+			// and is not trusted for an allocation
+			if size < 16 {
+				return 0, 0, errWAVFormat
+			}
+			var body [16]byte
+			if _, err := io.ReadFull(r, body[:]); err != nil {
+				return 0, 0, fmt.Errorf("wav fmt: %w", err)
+			}
+			if _, err := io.CopyN(io.Discard, r, size-16); err != nil {
+				return 0, 0, fmt.Errorf("wav fmt: %w", err)
+			}
+			if binary.LittleEndian.Uint16(body[0:2]) != 1 || // PCM
+				binary.LittleEndian.Uint16(body[2:4]) != channels ||
+				binary.LittleEndian.Uint32(body[4:8]) != sampleRate ||
+				binary.LittleEndian.Uint16(body[14:16]) != 16 {
+				return 0, 0, errWAVFormat
+			}
+			sawFmt = true
+		case "data":
+			if !sawFmt {
+				return 0, 0, errors.New("wav data before fmt chunk")
+			}
+			return pos, size, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return 0, 0, fmt.Errorf("wav chunk %q: %w", id, err)
+			}
+		}
+		pos += size
+		// chunks are word aligned
+		if size%2 == 1 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return 0, 0, err
+			}
+			pos++
+		}
+	}
+}
+
+func (s *wavSource) ReadFrame(dst []int16) error {
+	return readInt16Frame(s.reader, s.buf, dst)
+}
+
+func (s *wavSource) Close() error {
+	return s.f.Close()
+}
+
+// --- in-memory ---
+
+// pcmSource plays interleaved samples from memory: test clips, and the
+// empty source a seek past the end of a track plays.
+type pcmSource struct {
+	samples []int16
+	pos     int
+}
+
+func newPCMSource(samples []int16, offset time.Duration) *pcmSource {
+	pos := int(offset/frameDuration) * frameSize * channels
+	if pos > len(samples) {
+		pos = len(samples)
+	}
+	return &pcmSource{samples: samples, pos: pos}
+}
+
+func (s *pcmSource) ReadFrame(dst []int16) error {
+	if len(s.samples)-s.pos < len(dst) {
+		return io.EOF
+	}
+	copy(dst, s.samples[s.pos:])
+	s.pos += len(dst)
+	return nil
+}
+
+func (s *pcmSource) Close() error { return nil }
+
+// readInt16Frame reads one frame of little-endian samples using buf as
+// scratch space. A trailing partial frame counts as the end of the stream.
+func readInt16Frame(r io.Reader, buf []byte, dst []int16) error {
+	buf = buf[:len(dst)*2]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.EOF
+		}
+		return err
+	}
+	for i := 0; i < len(dst); i++ {
+		dst[i] = int16(binary.LittleEndian.Uint16(buf[i*2 : i*2+2]))
+	}
+	return nil
+}
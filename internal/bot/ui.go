@@ -3,6 +3,7 @@ package bot
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"musicbot/internal/musicapi"
 
@@ -16,18 +17,20 @@ type UIState struct {
 	Status      string
 	VoiceChanID string
 	RequestedBy string
+
+	Elapsed  time.Duration
+	Duration time.Duration // 0 when the track length is unknown
+	Live     bool          // radio stream: elapsed is time listened
+	Volume   int
+	Loop     LoopMode
+	Autoplay bool
 }
 
-func NowPlayingEmbed(d *musicapi.SongDetail, ui UIState) *discordgo.MessageEmbed {
-	title := strings.TrimSpace(d.Title)
-	if title == "" {
-		title = "Unknown Title"
-	}
+const progressBarWidth = 16
 
-	artist := strings.TrimSpace(d.Artist)
-	if artist == "" {
-		artist = "Unknown Artist"
-	}
+func NowPlayingEmbed(d *musicapi.SongDetail, ui UIState) *discordgo.MessageEmbed {
+	title := displayTitle(d)
+	artist := displayArtist(d)
 
 	status := strings.TrimSpace(ui.Status)
 	if status == "" {
@@ -57,7 +60,21 @@ func NowPlayingEmbed(d *musicapi.SongDetail, ui UIState) *discordgo.MessageEmbed
 	embed.Fields = []*discordgo.MessageEmbedField{
 		{
 			Name:   "Track",
-			Value:  fmt.Sprintf("**%s**", title),
+			Value:  fmt.Sprintf("**%s**%s", title, explicitTag(d)),
+			Inline: false,
+		},
+	}
+	if album := albumLine(d); album != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Album",
+			Value:  album,
+			Inline: false,
+		})
+	}
+	embed.Fields = append(embed.Fields, []*discordgo.MessageEmbedField{
+		{
+			Name:   "Progress",
+			Value:  progressText(ui),
 			Inline: false,
 		},
 		{
@@ -70,19 +87,34 @@ func NowPlayingEmbed(d *musicapi.SongDetail, ui UIState) *discordgo.MessageEmbed
 			Value:  req,
 			Inline: true,
 		},
-	}
+		{
+			Name:   "Volume",
+			Value:  fmt.Sprintf("`%d%%`", ui.Volume),
+			Inline: true,
+		},
+		{
+			Name:   "Loop",
+			Value:  fmt.Sprintf("`%s`", ui.Loop),
+			Inline: true,
+		},
+		{
+			Name:   "Autoplay",
+			Value:  fmt.Sprintf("`%s`", onOff(ui.Autoplay)),
+			Inline: true,
+		},
+	}...)
 
 	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text: "Pause/Resume toggles • Stop ends playback • Leave disconnects",
+		Text: "Pause/Resume toggles • ±10s seeks • Repeat cycles loop mode • Stop ends playback • Leave disconnects",
 	}
 
 	return embed
 }
 
 // PlayerControls returns modern controls in two rows (NO Open button):
-// Row 1: Toggle (Pause/Resume) + Stop
-// Row 2: Leave
-func PlayerControls(isPaused bool) []discordgo.MessageComponent {
+// Row 1: -10s + Toggle (Pause/Resume) + +10s + Stop
+// Row 2: Repeat (cycles off/track/queue) + Volume down + Volume up + Leave
+func PlayerControls(isPaused bool, loop LoopMode) []discordgo.MessageComponent {
 	// Toggle button (Pause ↔ Resume)
 	toggleLabel := "Pause"
 	toggleEmoji := "⏸️"
@@ -98,12 +130,24 @@ func PlayerControls(isPaused bool) []discordgo.MessageComponent {
 
 	row1 := discordgo.ActionsRow{
 		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				CustomID: ctrlBackID,
+				Label:    "-10s",
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "⏪"},
+			},
 			discordgo.Button{
 				CustomID: toggleID,
 				Label:    toggleLabel,
 				Style:    toggleStyle,
 				Emoji:    &discordgo.ComponentEmoji{Name: toggleEmoji},
 			},
+			discordgo.Button{
+				CustomID: ctrlForwardID,
+				Label:    "+10s",
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "⏩"},
+			},
 			discordgo.Button{
 				CustomID: ctrlStopID,
 				Label:    "Stop",
@@ -113,8 +157,39 @@ func PlayerControls(isPaused bool) []discordgo.MessageComponent {
 		},
 	}
 
+	repeatLabel := "Repeat: Off"
+	repeatEmoji := "🔁"
+	repeatStyle := discordgo.SecondaryButton
+	switch loop {
+	case LoopTrack:
+		repeatLabel = "Repeat: Track"
+		repeatEmoji = "🔂"
+		repeatStyle = discordgo.SuccessButton
+	case LoopQueue:
+		repeatLabel = "Repeat: Queue"
+		repeatStyle = discordgo.SuccessButton
+	}
+
 	row2 := discordgo.ActionsRow{
 		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				CustomID: ctrlRepeatID,
+				Label:    repeatLabel,
+				Style:    repeatStyle,
+				Emoji:    &discordgo.ComponentEmoji{Name: repeatEmoji},
+			},
+			discordgo.Button{
+				CustomID: ctrlVolDownID,
+				Label:    "Vol -",
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "🔉"},
+			},
+			discordgo.Button{
+				CustomID: ctrlVolUpID,
+				Label:    "Vol +",
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "🔊"},
+			},
 			discordgo.Button{
 				CustomID: ctrlLeaveID,
 				Label:    "Leave",
@@ -127,6 +202,34 @@ func PlayerControls(isPaused bool) []discordgo.MessageComponent {
 	return []discordgo.MessageComponent{row1, row2}
 }
 
+func progressText(ui UIState) string {
+	if ui.Live {
+		return fmt.Sprintf("🔴 LIVE `%s`", formatDuration(ui.Elapsed))
+	}
+	return progressBar(ui.Elapsed, ui.Duration)
+}
+
+// progressBar renders elapsed/total as a text bar, or just the elapsed time
+// when the track length is unknown.
+func progressBar(elapsed, total time.Duration) string {
+	if total <= 0 {
+		return fmt.Sprintf("`%s`", formatDuration(elapsed))
+	}
+	if elapsed > total {
+		elapsed = total
+	}
+	knob := int(float64(progressBarWidth-1) * float64(elapsed) / float64(total))
+	bar := strings.Repeat("▬", knob) + "🔘" + strings.Repeat("▬", progressBarWidth-1-knob)
+	return fmt.Sprintf("%s `%s / %s`", bar, formatDuration(elapsed), formatDuration(total))
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
+
 func mentionChannel(id string) string {
 	id = strings.TrimSpace(id)
 	if id == "" {
@@ -134,3 +237,180 @@ func mentionChannel(id string) string {
 	}
 	return "<#" + id + ">"
 }
+
+// queueListMax keeps the queue embed well under Discord's description limit.
+const queueListMax = 15
+
+func QueuedEmbed(d *musicapi.SongDetail, pos int, requestedBy string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "➕ Added to Queue",
+		Description: fmt.Sprintf("**%s**\n%s", displayTitle(d), displayArtist(d)),
+		Color:       uiColor,
+		URL:         d.Link,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Position", Value: fmt.Sprintf("`#%d`", pos), Inline: true},
+			{Name: "Length", Value: fmt.Sprintf("`%s`", lengthText(d)), Inline: true},
+			{Name: "Requested by", Value: requestedBy, Inline: true},
+		},
+	}
+	if d.Image != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: d.Image}
+	}
+	return embed
+}
+
+func QueueEmbed(current *QueueItem, upcoming []*QueueItem) *discordgo.MessageEmbed {
+	var sb strings.Builder
+	if current != nil {
+		fmt.Fprintf(&sb, "**Now:** %s — %s\n\n", trackTitle(current), displayArtist(current.Track))
+	}
+	if len(upcoming) == 0 {
+		sb.WriteString("_Nothing queued next._")
+	}
+	for n, item := range upcoming {
+		if n == queueListMax {
+			fmt.Fprintf(&sb, "…and %d more", len(upcoming)-queueListMax)
+			break
+		}
+		length := lengthText(item.Track)
+		if item.Live {
+			length = "LIVE"
+		}
+		fmt.Fprintf(&sb, "`%d.` %s — %s `%s` (%s)\n", n+1, trackTitle(item), displayArtist(item.Track), length, item.RequestedBy)
+	}
+
+	footer := fmt.Sprintf("%d track(s) up next", len(upcoming))
+	if total, unknown := queueLength(upcoming); total > 0 {
+		footer += " • " + formatDuration(total)
+		if unknown > 0 {
+			footer += "+"
+		}
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "📜 Queue",
+		Description: sb.String(),
+		Color:       uiColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: footer,
+		},
+	}
+}
+
+func trackTitle(item *QueueItem) string {
+	if item == nil {
+		return "Unknown Title"
+	}
+	return displayTitle(item.Track)
+}
+
+// displayTitle and displayArtist are escaped for use in markdown.
+func displayTitle(d *musicapi.SongDetail) string {
+	if d == nil || strings.TrimSpace(d.Title) == "" {
+		return "Unknown Title"
+	}
+	return musicapi.EscapeMarkdown(strings.TrimSpace(d.Title))
+}
+
+func displayArtist(d *musicapi.SongDetail) string {
+	if d == nil || strings.TrimSpace(d.Artist) == "" {
+		return "Unknown Artist"
+	}
+	return musicapi.EscapeMarkdown(strings.TrimSpace(d.Artist))
+}
+
+func explicitTag(d *musicapi.SongDetail) string {
+	if d != nil && d.Explicit {
+		return " 🅴"
+	}
+	return ""
+}
+
+// albumLine is "Album (Year)", or "" when the API gave neither.
+func albumLine(d *musicapi.SongDetail) string {
+	if d == nil {
+		return ""
+	}
+	album := musicapi.EscapeMarkdown(strings.TrimSpace(d.Album))
+	switch {
+	case album != "" && d.Year > 0:
+		return fmt.Sprintf("%s (%d)", album, d.Year)
+	case album != "":
+		return album
+	case d.Year > 0:
+		return fmt.Sprint(d.Year)
+	}
+	return ""
+}
+
+func lengthText(d *musicapi.SongDetail) string {
+	if d == nil || d.Duration <= 0 {
+		return "?:??"
+	}
+	return formatDuration(d.Duration)
+}
+
+// queueLength sums the known track lengths and counts the unknown ones.
+func queueLength(items []*QueueItem) (total time.Duration, unknown int) {
+	for _, item := range items {
+		if item.Track != nil && item.Track.Duration > 0 {
+			total += item.Track.Duration
+		} else {
+			unknown++
+		}
+	}
+	return total, unknown
+}
+
+// CollectionQueuedEmbed summarises an album or playlist added to the queue.
+// firstPos is the queue position of its first track (0: playing now);
+// skipped counts tracks left out over maxCollectionTracks.
+func CollectionQueuedEmbed(c *musicapi.Collection, kind string, queued, skipped, firstPos int, requestedBy string) *discordgo.MessageEmbed {
+	name := musicapi.EscapeMarkdown(strings.TrimSpace(c.Title))
+	if name == "" {
+		name = "this " + kind
+	} else {
+		name = "**" + name + "**"
+	}
+	desc := fmt.Sprintf("Queued %d track(s) from %s", queued, name)
+	if artist := strings.TrimSpace(c.Artist); artist != "" {
+		desc += " by " + musicapi.EscapeMarkdown(artist)
+	}
+	desc += "."
+	if skipped > 0 {
+		desc += fmt.Sprintf("\n_%d more left out (limit %d per %s)._", skipped, maxCollectionTracks, kind)
+	}
+
+	start := "`now`"
+	if firstPos > 0 {
+		start = fmt.Sprintf("`#%d`", firstPos)
+	}
+	items := make([]*QueueItem, 0, queued)
+	for n := 0; n < queued && n < len(c.Songs); n++ {
+		items = append(items, &QueueItem{Track: &c.Songs[n]})
+	}
+	length := "`unknown`"
+	if total, unknown := queueLength(items); total > 0 {
+		length = "`" + formatDuration(total)
+		if unknown > 0 {
+			length += "+"
+		}
+		length += "`"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "➕ Added " + kind,
+		Description: desc,
+		Color:       uiColor,
+		URL:         c.Link,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Starts at", Value: start, Inline: true},
+			{Name: "Length", Value: length, Inline: true},
+			{Name: "Requested by", Value: requestedBy, Inline: true},
+		},
+	}
+	if c.Image != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: c.Image}
+	}
+	return embed
+}
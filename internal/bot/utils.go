@@ -1,6 +1,13 @@
 package bot
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 func replyText(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) {
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -34,3 +41,80 @@ func truncate(in string, max int) string {
 	}
 	return string(r[:max-1]) + "…"
 }
+
+func optionInt(i *discordgo.InteractionCreate, name string) int {
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == name {
+			return int(o.IntValue())
+		}
+	}
+	return 0
+}
+
+func optionString(i *discordgo.InteractionCreate, name string) string {
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == name {
+			return strings.TrimSpace(o.StringValue())
+		}
+	}
+	return ""
+}
+
+// parsePosition accepts "ss", "mm:ss" or "hh:mm:ss".
+func parsePosition(in string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(in), ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return 0, fmt.Errorf("invalid position %q (use mm:ss)", in)
+	}
+	var total time.Duration
+	for n, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || (n > 0 && v > 59) {
+			return 0, fmt.Errorf("invalid position %q (use mm:ss)", in)
+		}
+		total = total*60 + time.Duration(v)
+	}
+	return total * time.Second, nil
+}
+
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+func hasOption(i *discordgo.InteractionCreate, name string) bool {
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func optionBool(i *discordgo.InteractionCreate, name string) bool {
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == name {
+			return o.BoolValue()
+		}
+	}
+	return false
+}
+
+// subcommand returns the invoked subcommand and its options by name.
+func subcommand(i *discordgo.InteractionCreate) (string, map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	opts := i.ApplicationCommandData().Options
+	if len(opts) == 0 || opts[0].Type != discordgo.ApplicationCommandOptionSubCommand {
+		return "", nil
+	}
+	byName := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(opts[0].Options))
+	for _, o := range opts[0].Options {
+		byName[o.Name] = o
+	}
+	return opts[0].Name, byName
+}
@@ -1,13 +1,10 @@
 package bot
 
 import (
-	"bufio"
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
 	"time"
 
 	"layeh.com/gopus"
@@ -18,6 +15,8 @@ const (
 	channels     = 2
 	frameSize    = 960  // 20ms @ 48kHz
 	maxOpusBytes = 4000 // max packet size
+
+	frameDuration = 20 * time.Millisecond
 )
 
 func (b *Bot) userVoiceChannelID(guildID, userID string) (string, error) {
@@ -44,47 +43,24 @@ func (b *Bot) userVoiceChannelID(guildID, userID string) (string, error) {
 	return "", errors.New("user not in a voice channel")
 }
 
-func (b *Bot) playURLWithPause(ctx context.Context, p *Player, audioURL string) error {
-	// Give discord voice connection a moment to be ready
-	time.Sleep(300 * time.Millisecond)
-
-	// ffmpeg: decode URL -> raw PCM s16le 48k stereo -> stdout
-	ff := exec.Command(
-		b.cfg.FFmpegPath,
-		"-reconnect", "1",
-		"-reconnect_streamed", "1",
-		"-reconnect_delay_max", "5",
-		"-i", audioURL,
-		"-f", "s16le",
-		"-ar", "48000",
-		"-ac", "2",
-		"pipe:1",
-	)
-
-	stdout, err := ff.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	stderr, _ := ff.StderrPipe()
-
-	if err := ff.Start(); err != nil {
-		return err
-	}
-	defer func() { _ = ff.Process.Kill() }()
-
-	// Drain stderr so ffmpeg never blocks (important!)
-	go func() { _, _ = io.Copy(io.Discard, stderr) }()
+// playSource sends src to the player's voice connection and closes it.
+// Speaking state is owned by the player session, not individual streams.
+func (b *Bot) playSource(ctx context.Context, p *Player, src AudioSource) error {
+	defer func() { _ = src.Close() }()
+	return sendFrames(ctx, p, src, p.vc.OpusSend)
+}
 
-	enc, err := gopus.NewEncoder(sampleRate, channels, gopus.Audio)
-	if err != nil {
-		return fmt.Errorf("opus encoder: %w", err)
+// sendFrames encodes src to Opus and feeds it to send until the source ends,
+// honouring pause and cancellation on p. send is vc.OpusSend in production.
+func sendFrames(ctx context.Context, p *Player, src AudioSource, send chan<- []byte) error {
+	if p.enc == nil {
+		enc, err := gopus.NewEncoder(sampleRate, channels, gopus.Audio)
+		if err != nil {
+			return fmt.Errorf("opus encoder: %w", err)
+		}
+		p.enc = enc
 	}
-
-	vc := p.vc
-	_ = vc.Speaking(true)
-	defer func() { _ = vc.Speaking(false) }()
-
-	reader := bufio.NewReaderSize(stdout, 1<<20)
+	enc := p.enc
 
 	pcmFrame := make([]int16, frameSize*channels)
 
@@ -96,19 +72,31 @@ func (b *Bot) playURLWithPause(ctx context.Context, p *Player, audioURL string)
 		default:
 		}
 
+		if p.dropping() {
+			if err := src.ReadFrame(pcmFrame); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("read pcm: %w", err)
+			}
+			continue
+		}
+
 		// Pause support (blocks here while paused)
 		if err := p.waitIfPaused(ctx); err != nil {
 			return err
 		}
 
 		// Read 20ms PCM frame
-		if err := readInt16Frame(reader, pcmFrame); err != nil {
+		if err := src.ReadFrame(pcmFrame); err != nil {
 			if errors.Is(err, io.EOF) {
-				break
+				return nil
 			}
 			return fmt.Errorf("read pcm: %w", err)
 		}
 
+		applyGain(pcmFrame, int(p.volume.Load()))
+
 		// gopus Encode returns []byte packet (NOT int)
 		packet, err := enc.Encode(pcmFrame, frameSize, maxOpusBytes)
 		if err != nil {
@@ -117,27 +105,12 @@ func (b *Bot) playURLWithPause(ctx context.Context, p *Player, audioURL string)
 
 		// Send opus packet to Discord
 		select {
-		case vc.OpusSend <- packet:
+		case send <- packet:
+			p.frames.Add(1)
 		case <-ctx.Done():
 			return errors.New("stopped")
 		case <-time.After(2 * time.Second):
 			return errors.New("opus send timeout (voice not ready)")
 		}
 	}
-
-	_ = ff.Wait()
-	return nil
-}
-
-func readInt16Frame(r *bufio.Reader, dst []int16) error {
-	// dst length is samples * channels
-	buf := make([]byte, len(dst)*2)
-	_, err := io.ReadFull(r, buf)
-	if err != nil {
-		return err
-	}
-	for i := 0; i < len(dst); i++ {
-		dst[i] = int16(binary.LittleEndian.Uint16(buf[i*2 : i*2+2]))
-	}
-	return nil
 }
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestPlayer() *Player {
+	p := &Player{guildID: "test"}
+	p.cond = sync.NewCond(&p.mu)
+	p.volume.Store(100)
+	return p
+}
+
+// testTone returns n frames of a quiet square wave.
+func testTone(n int) []int16 {
+	samples := make([]int16, n*frameSize*channels)
+	for i := range samples {
+		if (i/96)%2 == 0 {
+			samples[i] = 3000
+		} else {
+			samples[i] = -3000
+		}
+	}
+	return samples
+}
+
+// runSendFrames starts sendFrames in the background and returns a channel
+// carrying its result.
+func runSendFrames(ctx context.Context, p *Player, src AudioSource, send chan []byte) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- sendFrames(ctx, p, src, send) }()
+	return done
+}
+
+func waitResult(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("sendFrames did not return")
+		return nil
+	}
+}
+
+func TestSendFramesSendsEveryFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames int
+		offset time.Duration
+		want   int
+	}{
+		{"whole clip", 10, 0, 10},
+		{"with offset", 10, 4 * frameDuration, 6},
+		{"offset past end", 10, time.Second, 0},
+		{"empty", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer()
+			send := make(chan []byte, tt.frames+1)
+			src := newPCMSource(testTone(tt.frames), tt.offset)
+
+			if err := sendFrames(context.Background(), p, src, send); err != nil {
+				t.Fatalf("sendFrames: %v", err)
+			}
+			if len(send) != tt.want {
+				t.Errorf("sent %d packets, want %d", len(send), tt.want)
+			}
+			if got := p.frames.Load(); got != int64(tt.want) {
+				t.Errorf("p.frames = %d, want %d", got, tt.want)
+			}
+			for len(send) > 0 {
+				if pkt := <-send; len(pkt) == 0 {
+					t.Error("empty opus packet")
+				}
+			}
+		})
+	}
+}
+
+func TestSendFramesIgnoresTrailingPartialFrame(t *testing.T) {
+	p := newTestPlayer()
+	send := make(chan []byte, 8)
+	samples := append(testTone(3), make([]int16, frameSize)...) // half a frame more
+
+	if err := sendFrames(context.Background(), p, newPCMSource(samples, 0), send); err != nil {
+		t.Fatalf("sendFrames: %v", err)
+	}
+	if got := p.frames.Load(); got != 3 {
+		t.Errorf("p.frames = %d, want 3", got)
+	}
+}
+
+func TestSendFramesBlocksWhilePaused(t *testing.T) {
+	p := newTestPlayer()
+	p.paused = true
+	send := make(chan []byte, 8)
+
+	done := runSendFrames(context.Background(), p, newPCMSource(testTone(5), 0), send)
+
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("sendFrames returned while paused: %v", err)
+	default:
+	}
+	if len(send) != 0 || p.frames.Load() != 0 {
+		t.Fatalf("sent %d packets while paused", len(send))
+	}
+
+	p.mu.Lock()
+	p.paused = false
+	p.mu.Unlock()
+	p.cond.Broadcast()
+
+	if err := waitResult(t, done); err != nil {
+		t.Fatalf("sendFrames: %v", err)
+	}
+	if len(send) != 5 || p.frames.Load() != 5 {
+		t.Errorf("sent %d packets (frames %d) after resume, want 5", len(send), p.frames.Load())
+	}
+}
+
+func TestSendFramesStopsOnCancel(t *testing.T) {
+	t.Run("while sending", func(t *testing.T) {
+		p := newTestPlayer()
+		send := make(chan []byte) // nobody reads after the first three
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := runSendFrames(ctx, p, newPCMSource(testTone(50), 0), send)
+		for n := 0; n < 3; n++ {
+			select {
+			case <-send:
+			case <-time.After(time.Second):
+				t.Fatal("no packet")
+			}
+		}
+		cancel()
+
+		if err := waitResult(t, done); err == nil {
+			t.Fatal("sendFrames returned nil after cancel")
+		}
+		if got := p.frames.Load(); got != 3 {
+			t.Errorf("p.frames = %d, want 3", got)
+		}
+	})
+
+	t.Run("while paused", func(t *testing.T) {
+		p := newTestPlayer()
+		p.paused = true
+		send := make(chan []byte, 8)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := runSendFrames(ctx, p, newPCMSource(testTone(5), 0), send)
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		p.stop() // wakes the paused loop, as Stop does
+
+		if err := waitResult(t, done); err == nil {
+			t.Fatal("sendFrames returned nil after cancel")
+		}
+		if len(send) != 0 || p.frames.Load() != 0 {
+			t.Errorf("sent %d packets, want 0", len(send))
+		}
+	})
+}
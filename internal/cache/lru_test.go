@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictionOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		ops     []string // "+k" adds k, "?k" gets k
+		present []string
+		evicted []string
+	}{
+		{
+			name:    "oldest goes first",
+			size:    2,
+			ops:     []string{"+a", "+b", "+c"},
+			present: []string{"b", "c"},
+			evicted: []string{"a"},
+		},
+		{
+			name:    "get refreshes recency",
+			size:    2,
+			ops:     []string{"+a", "+b", "?a", "+c"},
+			present: []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:    "re-add refreshes recency",
+			size:    2,
+			ops:     []string{"+a", "+b", "+a", "+c"},
+			present: []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:    "size below one holds one",
+			size:    0,
+			ops:     []string{"+a", "+b"},
+			present: []string{"b"},
+			evicted: []string{"a"},
+		},
+		{
+			name:    "under capacity keeps all",
+			size:    3,
+			ops:     []string{"+a", "+b", "+c"},
+			present: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](tt.size, 0)
+			for n, op := range tt.ops {
+				switch op[0] {
+				case '+':
+					c.Add(op[1:], n)
+				case '?':
+					c.Get(op[1:])
+				}
+			}
+			for _, k := range tt.present {
+				if _, ok := c.Get(k); !ok {
+					t.Errorf("%q missing", k)
+				}
+			}
+			for _, k := range tt.evicted {
+				if _, ok := c.Get(k); ok {
+					t.Errorf("%q still cached", k)
+				}
+			}
+			if got := c.Stats().Evicted; got != uint64(len(tt.evicted)) {
+				t.Errorf("Evicted = %d, want %d", got, len(tt.evicted))
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		wait  time.Duration
+		found bool
+	}{
+		{"fresh", ttl, 0, true},
+		{"expired", ttl, 2 * ttl, false},
+		{"no ttl never expires", 0, 2 * ttl, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](4, tt.ttl)
+			c.Add("k", 1)
+			time.Sleep(tt.wait)
+			if _, ok := c.Get("k"); ok != tt.found {
+				t.Errorf("Get found = %v, want %v", ok, tt.found)
+			}
+			if !tt.found && c.Stats().Len != 0 {
+				t.Error("expired entry not removed on Get")
+			}
+		})
+	}
+
+	t.Run("re-add resets expiry", func(t *testing.T) {
+		c := New[string, int](4, ttl)
+		c.Add("k", 1)
+		time.Sleep(ttl * 3 / 5)
+		c.Add("k", 2)
+		time.Sleep(ttl * 3 / 5)
+		if v, ok := c.Get("k"); !ok || v != 2 {
+			t.Errorf("Get = %d, %v; want 2, true", v, ok)
+		}
+	})
+}
+
+func TestLRUStats(t *testing.T) {
+	c := New[string, int](2, 0)
+	c.Get("a") // miss
+	c.Add("a", 1)
+	c.Get("a") // hit
+	c.Get("a") // hit
+	c.Add("b", 2)
+	c.Add("c", 3) // evicts a
+	c.Get("a")    // miss
+	c.Remove("b")
+
+	want := Stats{Hits: 2, Misses: 2, Evicted: 1, Len: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+
+	c.Purge()
+	want.Len = 0
+	if got := c.Stats(); got != want {
+		t.Errorf("after Purge: Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestLRURemove(t *testing.T) {
+	c := New[string, int](8, 0)
+	for _, k := range []string{"a", "a/1", "a/2", "b", "ab"} {
+		c.Add(k, 0)
+	}
+	if !c.Remove("b") || c.Remove("b") {
+		t.Error("Remove should report presence once")
+	}
+	n := c.RemoveFunc(func(k string) bool { return k == "a" || strings.HasPrefix(k, "a/") })
+	if n != 3 {
+		t.Errorf("RemoveFunc removed %d, want 3", n)
+	}
+	if _, ok := c.Get("ab"); !ok {
+		t.Error("RemoveFunc removed a key that didn't match")
+	}
+	if got := c.Stats().Len; got != 1 {
+		t.Errorf("Len = %d, want 1", got)
+	}
+}
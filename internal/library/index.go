@@ -0,0 +1,53 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// indexFile is the on-disk format of the library index.
+type indexFile struct {
+	Version int      `json:"version"`
+	Tracks  []*Track `json:"tracks"`
+}
+
+const indexVersion = 1
+
+func loadIndex(path string) ([]*Track, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var idx indexFile
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return nil, err
+	}
+	if idx.Version != indexVersion {
+		return nil, nil // rebuilt by the next scan
+	}
+	return idx.Tracks, nil
+}
+
+// saveIndex writes the index atomically so a crash never leaves half a file.
+func saveIndex(path string, tracks map[string]*Track) error {
+	idx := indexFile{Version: indexVersion, Tracks: make([]*Track, 0, len(tracks))}
+	for _, t := range tracks {
+		idx.Tracks = append(idx.Tracks, t)
+	}
+	sort.Slice(idx.Tracks, func(i, j int) bool { return idx.Tracks[i].Path < idx.Tracks[j].Path })
+
+	raw, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
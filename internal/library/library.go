@@ -0,0 +1,329 @@
+// Package library serves songs from local audio files so the bot can play
+// a music collection, or run fully offline for testing.
+package library
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"musicbot/internal/musicapi"
+)
+
+// IDPrefix marks song IDs that belong to the local library.
+const IDPrefix = "local:"
+
+var audioExts = map[string]bool{
+	".mp3": true, ".flac": true, ".ogg": true, ".opus": true,
+	".m4a": true, ".wav": true,
+}
+
+// Options configures a library Provider.
+type Options struct {
+	Dirs      []string
+	IndexPath string // JSON index file; empty keeps the index in memory only
+	CoverDir  string // where embedded covers are extracted; empty disables covers
+	CoverURL  string // public URL CoverDir is served under (see CoverHandler)
+}
+
+// Track is one indexed audio file. Size and ModTime let a rescan skip
+// files that haven't changed.
+type Track struct {
+	ID      string    `json:"id"`
+	Path    string    `json:"path"`
+	Title   string    `json:"title"`
+	Artist  string    `json:"artist,omitempty"`
+	Album   string    `json:"album,omitempty"`
+	Cover   string    `json:"cover,omitempty"` // file name in CoverDir
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// Provider indexes audio files under a set of directories and implements
+// musicapi.Provider over them.
+type Provider struct {
+	opts Options
+
+	scanMu sync.Mutex // one scan at a time
+
+	mu     sync.RWMutex
+	tracks map[string]*Track // ID -> track
+}
+
+// New creates a provider and loads the persisted index, if any. Call Scan
+// to pick up changes on disk.
+func New(opts Options) *Provider {
+	p := &Provider{opts: opts, tracks: make(map[string]*Track)}
+	if opts.IndexPath != "" {
+		tracks, err := loadIndex(opts.IndexPath)
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("library: load index: %v", err)
+		}
+		for _, t := range tracks {
+			p.tracks[t.ID] = t
+		}
+	}
+	return p
+}
+
+// Scan walks every directory and updates the index. Files whose size and
+// modification time match the index are not re-read.
+func (p *Provider) Scan() error {
+	p.scanMu.Lock()
+	defer p.scanMu.Unlock()
+
+	p.mu.RLock()
+	old := p.tracks
+	p.mu.RUnlock()
+
+	tracks := make(map[string]*Track, len(old))
+	changed := 0
+	for _, dir := range p.opts.Dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Printf("library: %v", err)
+				return nil
+			}
+			if d.IsDir() || !audioExts[strings.ToLower(filepath.Ext(path))] {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				abs = path
+			}
+
+			id := trackID(abs)
+			if t := old[id]; t != nil && t.Size == info.Size() && t.ModTime.Equal(info.ModTime()) {
+				tracks[id] = t
+				return nil
+			}
+			tracks[id] = p.indexFile(abs, info)
+			changed++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	removed := 0
+	for id := range old {
+		if tracks[id] == nil {
+			removed++
+		}
+	}
+
+	p.mu.Lock()
+	p.tracks = tracks
+	p.mu.Unlock()
+
+	if changed == 0 && removed == 0 {
+		return nil
+	}
+	log.Printf("library: %d tracks (%d new or changed, %d removed)", len(tracks), changed, removed)
+	p.pruneCovers(tracks)
+	if p.opts.IndexPath != "" {
+		if err := saveIndex(p.opts.IndexPath, tracks); err != nil {
+			log.Printf("library: save index: %v", err)
+		}
+	}
+	return nil
+}
+
+// Watch rescans the library every interval until ctx is done.
+func (p *Provider) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := p.Scan(); err != nil {
+				log.Printf("library: rescan: %v", err)
+			}
+		}
+	}
+}
+
+// CoverHandler serves extracted cover art; mount it so that CoverURL points at it.
+// Only exact cover file names are served: no directory listings, and
+// nothing else that may live in CoverDir.
+func (p *Provider) CoverHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if !coverName.MatchString(name) {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(p.opts.CoverDir, name))
+	})
+}
+
+func (p *Provider) indexFile(path string, info fs.FileInfo) *Track {
+	tags := readTags(path)
+	t := &Track{
+		ID:      trackID(path),
+		Path:    path,
+		Title:   tags.Title,
+		Artist:  tags.Artist,
+		Album:   tags.Album,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if t.Title == "" {
+		t.Title, t.Artist = titleFromFilename(path, t.Artist)
+	}
+	if len(tags.Picture) > 0 {
+		t.Cover = p.saveCover(t.ID, tags.MIME, tags.Picture)
+	}
+	return t
+}
+
+func (p *Provider) saveCover(id, mime string, data []byte) string {
+	if p.opts.CoverDir == "" {
+		return ""
+	}
+	ext := ".jpg"
+	if strings.Contains(mime, "png") {
+		ext = ".png"
+	}
+	name := strings.TrimPrefix(id, IDPrefix) + ext
+	if err := os.MkdirAll(p.opts.CoverDir, 0o755); err != nil {
+		log.Printf("library: cover dir: %v", err)
+		return ""
+	}
+	if err := os.WriteFile(filepath.Join(p.opts.CoverDir, name), data, 0o644); err != nil {
+		log.Printf("library: save cover: %v", err)
+		return ""
+	}
+	return name
+}
+
+// coverName matches the file names saveCover writes: the hex part of a
+// track ID plus an image extension.
+var coverName = regexp.MustCompile(`^[0-9a-f]{16}\.(jpg|png)$`)
+
+// pruneCovers deletes extracted covers no indexed track refers to. Only
+// files named like saveCover's are touched, so a CoverDir shared with
+// anything else keeps its other files.
+func (p *Provider) pruneCovers(tracks map[string]*Track) {
+	if p.opts.CoverDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(p.opts.CoverDir)
+	if err != nil {
+		return
+	}
+	keep := make(map[string]bool, len(tracks))
+	for _, t := range tracks {
+		keep[t.Cover] = true
+	}
+	for _, e := range entries {
+		if !e.IsDir() && coverName.MatchString(e.Name()) && !keep[e.Name()] {
+			_ = os.Remove(filepath.Join(p.opts.CoverDir, e.Name()))
+		}
+	}
+}
+
+// trackID is stable for a path and short enough for a select menu value.
+func trackID(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return IDPrefix + hex.EncodeToString(sum[:8])
+}
+
+// titleFromFilename handles the common "Artist - Title.ext" naming.
+func titleFromFilename(path, artist string) (string, string) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if a, t, ok := strings.Cut(name, " - "); ok && artist == "" {
+		return strings.TrimSpace(t), strings.TrimSpace(a)
+	}
+	return name, artist
+}
+
+func (p *Provider) Search(ctx context.Context, query string) ([]musicapi.SongLite, error) {
+	terms := strings.Fields(strings.ToLower(query))
+
+	p.mu.RLock()
+	var hits []*Track
+	for _, t := range p.tracks {
+		if matches(t, terms) {
+			hits = append(hits, t)
+		}
+	}
+	p.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Artist != hits[j].Artist {
+			return hits[i].Artist < hits[j].Artist
+		}
+		return hits[i].Title < hits[j].Title
+	})
+
+	out := make([]musicapi.SongLite, len(hits))
+	for i, t := range hits {
+		out[i] = p.song(t)
+	}
+	return out, nil
+}
+
+func (p *Provider) Resolve(ctx context.Context, id string) (*musicapi.SongDetail, error) {
+	if !strings.HasPrefix(id, IDPrefix) {
+		return nil, musicapi.ErrNotFound
+	}
+	p.mu.RLock()
+	t := p.tracks[id]
+	p.mu.RUnlock()
+	if t == nil {
+		return nil, musicapi.ErrNotFound
+	}
+	return &musicapi.SongDetail{SongLite: p.song(t), Album: musicapi.CleanText(t.Album)}, nil
+}
+
+// Related returns other tracks by the same artist.
+func (p *Provider) Related(ctx context.Context, d *musicapi.SongDetail) ([]musicapi.SongLite, error) {
+	artist := d.PrimaryArtist()
+	if artist == "" {
+		return nil, nil
+	}
+	return p.Search(ctx, artist)
+}
+
+func matches(t *Track, terms []string) bool {
+	hay := strings.ToLower(t.Title + " " + t.Artist + " " + t.Album + " " + filepath.Base(t.Path))
+	for _, term := range terms {
+		if !strings.Contains(hay, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Provider) song(t *Track) musicapi.SongLite {
+	artists := musicapi.SplitArtists(musicapi.CleanText(t.Artist))
+	s := musicapi.SongLite{
+		ID:        t.ID,
+		Title:     musicapi.CleanText(t.Title),
+		Artist:    musicapi.JoinArtists(artists),
+		Artists:   artists,
+		StreamURL: t.Path, // ffmpeg reads local paths directly
+	}
+	if t.Cover != "" && p.opts.CoverURL != "" {
+		s.Image = strings.TrimRight(p.opts.CoverURL, "/") + "/" + t.Cover
+	}
+	return s
+}
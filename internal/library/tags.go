@@ -0,0 +1,384 @@
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxTagBytes caps how much of a file we read looking for metadata, so a
+// corrupt length field can't make us allocate gigabytes.
+const maxTagBytes = 16 << 20
+
+var errNoTag = errors.New("no supported tag")
+
+// Tags is the metadata we index from an audio file.
+type Tags struct {
+	Title   string
+	Artist  string
+	Album   string
+	Picture []byte // embedded cover art, if any
+	MIME    string // of Picture
+}
+
+// readTags reads ID3v2 (MP3), FLAC metadata blocks or Ogg Vorbis/Opus
+// comments, depending on what the file starts with. Missing or unreadable
+// tags yield an empty Tags.
+func readTags(path string) Tags {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(io.LimitReader(f, maxTagBytes))
+	magic, _ := r.Peek(4)
+
+	// a truncated tag still yields whatever was parsed before the error
+	var t Tags
+	switch {
+	case bytes.HasPrefix(magic, []byte("ID3")):
+		t, _ = readID3v2(r)
+	case bytes.Equal(magic, []byte("fLaC")):
+		t, _ = readFLAC(r)
+	case bytes.Equal(magic, []byte("OggS")):
+		t, _ = readOgg(r)
+	}
+	return t
+}
+
+// --- ID3v2 ---
+
+// readID3v2 reads the text and picture frames of an ID3v2.3/2.4 tag.
+func readID3v2(r io.Reader) (Tags, error) {
+	var hdr [10]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return Tags{}, err
+	}
+	if string(hdr[0:3]) != "ID3" {
+		return Tags{}, errNoTag
+	}
+	version, flags := hdr[3], hdr[5]
+	if version < 3 || version > 4 {
+		return Tags{}, errNoTag
+	}
+	// The header's size is only trusted as an upper bound: the buffer grows
+	// with what the file actually holds, and never past maxTagBytes. A
+	// truncated body parses up to the first incomplete frame.
+	size := min(syncsafe(hdr[6:10]), maxTagBytes)
+	body, err := io.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return Tags{}, err
+	}
+
+	// skip the extended header
+	if flags&0x40 != 0 && len(body) >= 4 {
+		n := int(binary.BigEndian.Uint32(body[0:4])) + 4
+		if version == 4 {
+			n = syncsafe(body[0:4])
+		}
+		if n > len(body) {
+			return Tags{}, errNoTag
+		}
+		body = body[n:]
+	}
+
+	var t Tags
+	for len(body) >= 10 && body[0] != 0 {
+		id := string(body[0:4])
+		var n int
+		if version == 4 {
+			n = syncsafe(body[4:8])
+		} else {
+			n = int(binary.BigEndian.Uint32(body[4:8]))
+		}
+		body = body[10:]
+		if n < 0 || n > len(body) {
+			break
+		}
+		frame := body[:n]
+		body = body[n:]
+
+		switch id {
+		case "TIT2":
+			t.Title = decodeID3Text(frame)
+		case "TPE1":
+			t.Artist = decodeID3Text(frame)
+		case "TALB":
+			t.Album = decodeID3Text(frame)
+		case "APIC":
+			if t.Picture == nil {
+				t.MIME, t.Picture = decodeAPIC(frame)
+			}
+		}
+	}
+	return t, nil
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
+
+// decodeID3Text decodes a text frame body: one encoding byte, then text.
+func decodeID3Text(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	s := decodeID3String(b[0], b[1:])
+	// multiple values are NUL separated; keep the first
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func decodeID3String(enc byte, b []byte) string {
+	switch enc {
+	case 1, 2: // UTF-16 with BOM / UTF-16BE
+		return decodeUTF16(b, enc == 2)
+	case 3: // UTF-8
+		return string(b)
+	default: // ISO-8859-1
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		return string(r)
+	}
+}
+
+// decodeAPIC splits an attached picture frame:
+// encoding, MIME\0, picture type, description\0, data.
+func decodeAPIC(b []byte) (mime string, data []byte) {
+	if len(b) < 4 {
+		return "", nil
+	}
+	enc := b[0]
+	b = b[1:]
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil
+	}
+	mime, b = string(b[:i]), b[i+1:]
+	if len(b) < 1 {
+		return "", nil
+	}
+	b = b[1:] // picture type
+
+	// description terminator is one NUL, or two for UTF-16
+	if enc == 1 || enc == 2 {
+		for i = 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				break
+			}
+		}
+		i += 2
+	} else {
+		i = bytes.IndexByte(b, 0) + 1
+	}
+	if i <= 0 || i > len(b) {
+		return "", nil
+	}
+	if mime == "" || !strings.Contains(mime, "/") {
+		mime = "image/" + strings.ToLower(mime) // v2.2-style "JPG"/"PNG"
+	}
+	return mime, b[i:]
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	if len(b) >= 2 {
+		switch {
+		case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+			bigEndian, b = false, b[2:]
+		case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+			bigEndian, b = true, b[2:]
+		}
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		if bigEndian {
+			u[i] = binary.BigEndian.Uint16(b[i*2:])
+		} else {
+			u[i] = binary.LittleEndian.Uint16(b[i*2:])
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// --- FLAC ---
+
+const (
+	flacVorbisComment = 4
+	flacPicture       = 6
+)
+
+// readFLAC walks the metadata blocks after the "fLaC" marker.
+func readFLAC(r io.Reader) (Tags, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return Tags{}, err
+	}
+
+	var t Tags
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return t, err
+		}
+		last := hdr[0]&0x80 != 0
+		kind := hdr[0] & 0x7F
+		n := int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3])
+
+		switch kind {
+		case flacVorbisComment, flacPicture:
+			block := make([]byte, n)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return t, err
+			}
+			if kind == flacVorbisComment {
+				parseVorbisComment(block, &t)
+			} else if t.Picture == nil {
+				t.MIME, t.Picture = parseFLACPicture(block)
+			}
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+				return t, err
+			}
+		}
+		if last {
+			return t, nil
+		}
+	}
+}
+
+// parseFLACPicture decodes a FLAC PICTURE block (also used base64-encoded
+// in Ogg METADATA_BLOCK_PICTURE comments).
+func parseFLACPicture(b []byte) (mime string, data []byte) {
+	next := func(n int) []byte {
+		if n < 0 || n > len(b) {
+			b = nil
+			return nil
+		}
+		out := b[:n]
+		b = b[n:]
+		return out
+	}
+	u32 := func() int {
+		v := next(4)
+		if v == nil {
+			return -1
+		}
+		return int(binary.BigEndian.Uint32(v))
+	}
+
+	u32() // picture type
+	mime = string(next(u32()))
+	next(u32()) // description
+	next(16)    // width, height, depth, colours
+	data = next(u32())
+	return mime, data
+}
+
+// --- Vorbis comments (FLAC, Ogg Vorbis, Opus) ---
+
+func parseVorbisComment(b []byte, t *Tags) {
+	u32 := func() int {
+		if len(b) < 4 {
+			return -1
+		}
+		v := int(binary.LittleEndian.Uint32(b))
+		b = b[4:]
+		return v
+	}
+
+	vendor := u32()
+	if vendor < 0 || vendor > len(b) {
+		return
+	}
+	b = b[vendor:]
+
+	count := u32()
+	for i := 0; i < count; i++ {
+		n := u32()
+		if n < 0 || n > len(b) {
+			return
+		}
+		key, value, ok := strings.Cut(string(b[:n]), "=")
+		b = b[n:]
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			if t.Title == "" {
+				t.Title = strings.TrimSpace(value)
+			}
+		case "ARTIST":
+			if t.Artist == "" {
+				t.Artist = strings.TrimSpace(value)
+			}
+		case "ALBUM":
+			if t.Album == "" {
+				t.Album = strings.TrimSpace(value)
+			}
+		case "METADATA_BLOCK_PICTURE":
+			if t.Picture == nil {
+				if raw, err := base64.StdEncoding.DecodeString(value); err == nil {
+					t.MIME, t.Picture = parseFLACPicture(raw)
+				}
+			}
+		}
+	}
+}
+
+// --- Ogg ---
+
+// readOgg reassembles the first Ogg packets and parses the comment header
+// (second packet) of a Vorbis or Opus stream.
+func readOgg(r io.Reader) (Tags, error) {
+	var packets [][]byte
+	var cur []byte
+	for len(packets) < 2 {
+		var hdr [27]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return Tags{}, err
+		}
+		if string(hdr[0:4]) != "OggS" {
+			return Tags{}, errNoTag
+		}
+		lacing := make([]byte, hdr[26])
+		if _, err := io.ReadFull(r, lacing); err != nil {
+			return Tags{}, err
+		}
+		for _, l := range lacing {
+			seg := make([]byte, l)
+			if _, err := io.ReadFull(r, seg); err != nil {
+				return Tags{}, err
+			}
+			cur = append(cur, seg...)
+			if l < 255 {
+				packets = append(packets, cur)
+				cur = nil
+			}
+		}
+	}
+
+	comment := packets[1]
+	var t Tags
+	switch {
+	case bytes.HasPrefix(comment, []byte("\x03vorbis")):
+		parseVorbisComment(comment[7:], &t)
+	case bytes.HasPrefix(comment, []byte("OpusTags")):
+		parseVorbisComment(comment[8:], &t)
+	default:
+		return Tags{}, errNoTag
+	}
+	return t, nil
+}
@@ -0,0 +1,285 @@
+package library
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// --- fixture builders ---
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+func id3Frame(version byte, id string, body []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(id)
+	if version == 4 {
+		b.Write(syncsafeBytes(len(body)))
+	} else {
+		_ = binary.Write(&b, binary.BigEndian, uint32(len(body)))
+	}
+	b.Write([]byte{0, 0}) // flags
+	b.Write(body)
+	return b.Bytes()
+}
+
+// id3Tag builds a tag whose header claims size bytes; -1 means the real size.
+func id3Tag(version, flags byte, size int, body ...[]byte) []byte {
+	payload := bytes.Join(body, nil)
+	if size < 0 {
+		size = len(payload)
+	}
+	hdr := append([]byte{'I', 'D', '3', version, 0, flags}, syncsafeBytes(size)...)
+	return append(hdr, payload...)
+}
+
+func latin1(s string) []byte   { return append([]byte{0}, s...) }
+func utf8Text(s string) []byte { return append([]byte{3}, s...) }
+
+func flacBlock(kind byte, last bool, data []byte) []byte {
+	if last {
+		kind |= 0x80
+	}
+	n := len(data)
+	return append([]byte{kind, byte(n >> 16), byte(n >> 8), byte(n)}, data...)
+}
+
+func vorbisComment(comments ...string) []byte {
+	var b bytes.Buffer
+	le := func(n int) { _ = binary.Write(&b, binary.LittleEndian, uint32(n)) }
+	le(len("test"))
+	b.WriteString("test")
+	le(len(comments))
+	for _, c := range comments {
+		le(len(c))
+		b.WriteString(c)
+	}
+	return b.Bytes()
+}
+
+func flacPictureData(mime string, data []byte) []byte {
+	var b bytes.Buffer
+	be := func(n int) { _ = binary.Write(&b, binary.BigEndian, uint32(n)) }
+	be(3) // front cover
+	be(len(mime))
+	b.WriteString(mime)
+	be(0) // description
+	b.Write(make([]byte, 16))
+	be(len(data))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// oggPage wraps whole packets in one page; the parser ignores CRCs.
+func oggPage(packets ...[]byte) []byte {
+	var lacing, body []byte
+	for _, p := range packets {
+		n := len(p)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, p...)
+	}
+	hdr := make([]byte, 27)
+	copy(hdr, "OggS")
+	hdr[26] = byte(len(lacing))
+	return append(append(hdr, lacing...), body...)
+}
+
+var cover = []byte{0xFF, 0xD8, 0xFF, 0xE0, 1, 2, 3}
+
+func TestReadTags(t *testing.T) {
+	apic := append(append([]byte{0}, "image/jpeg\x00\x03cover\x00"...), cover...)
+	utf16LE := []byte{1, 0xFF, 0xFE, 'H', 0, 'i', 0}
+	utf16BE := []byte{2, 0, 'H', 0, 'o'}
+	longComment := vorbisComment("TITLE=" + string(bytes.Repeat([]byte("x"), 300)))
+
+	tests := []struct {
+		name string
+		file []byte
+		want Tags
+	}{
+		{
+			name: "id3v2.3 latin-1",
+			file: id3Tag(3, 0, -1,
+				id3Frame(3, "TIT2", latin1("Caf\xe9")),
+				id3Frame(3, "TPE1", latin1("Artist")),
+				id3Frame(3, "TALB", latin1("Album")),
+			),
+			want: Tags{Title: "Café", Artist: "Artist", Album: "Album"},
+		},
+		{
+			name: "id3v2.4 utf-8 with syncsafe frame sizes",
+			file: id3Tag(4, 0, -1,
+				id3Frame(4, "TIT2", utf8Text(string(bytes.Repeat([]byte("é"), 100)))),
+				id3Frame(4, "TPE1", utf8Text("Ünïcode")),
+			),
+			want: Tags{Title: string(bytes.Repeat([]byte("é"), 100)), Artist: "Ünïcode"},
+		},
+		{
+			name: "id3 utf-16 with BOM and big-endian",
+			file: id3Tag(3, 0, -1,
+				id3Frame(3, "TIT2", utf16LE),
+				id3Frame(3, "TPE1", utf16BE),
+			),
+			want: Tags{Title: "Hi", Artist: "Ho"},
+		},
+		{
+			name: "id3 multiple values keep the first",
+			file: id3Tag(4, 0, -1, id3Frame(4, "TPE1", utf8Text("One\x00Two"))),
+			want: Tags{Artist: "One"},
+		},
+		{
+			name: "id3 attached picture",
+			file: id3Tag(3, 0, -1, id3Frame(3, "TIT2", latin1("T")), id3Frame(3, "APIC", apic)),
+			want: Tags{Title: "T", MIME: "image/jpeg", Picture: cover},
+		},
+		{
+			name: "id3v2.3 extended header is skipped",
+			file: id3Tag(3, 0x40, -1,
+				[]byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0},
+				id3Frame(3, "TIT2", latin1("After")),
+			),
+			want: Tags{Title: "After"},
+		},
+		{
+			name: "id3 padding ends the frames",
+			file: id3Tag(3, 0, -1, id3Frame(3, "TIT2", latin1("T")), make([]byte, 32)),
+			want: Tags{Title: "T"},
+		},
+		{
+			name: "id3 oversized header size",
+			file: id3Tag(3, 0, 0x0fffffff, id3Frame(3, "TIT2", latin1("Big"))),
+			want: Tags{Title: "Big"},
+		},
+		{
+			name: "id3 corrupt syncsafe size",
+			file: append([]byte("ID3\x03\x00\x00\xff\xff\xff\xff"), id3Frame(3, "TIT2", latin1("Bad"))...),
+			want: Tags{Title: "Bad"},
+		},
+		{
+			name: "id3 frame longer than the tag",
+			file: id3Tag(3, 0, -1,
+				id3Frame(3, "TIT2", latin1("Kept")),
+				append([]byte("TPE1\x00\x00\x00\x32\x00\x00"), latin1("Cut")...), // claims 50 bytes
+			),
+			want: Tags{Title: "Kept"},
+		},
+		{
+			name: "id3 truncated header",
+			file: []byte("ID3\x03\x00"),
+		},
+		{
+			name: "id3v2.2 is not supported",
+			file: id3Tag(2, 0, -1, []byte("TT2\x00\x00\x02\x00T")),
+		},
+		{
+			name: "flac vorbis comment",
+			file: append([]byte("fLaC"), bytes.Join([][]byte{
+				flacBlock(0, false, make([]byte, 34)), // STREAMINFO
+				flacBlock(flacVorbisComment, true, vorbisComment(
+					"title=Lower Key", "ARTIST=First", "ARTIST=Second", "Album=LP", "NOEQUALS",
+				)),
+			}, nil)...),
+			want: Tags{Title: "Lower Key", Artist: "First", Album: "LP"},
+		},
+		{
+			name: "flac picture",
+			file: append([]byte("fLaC"), bytes.Join([][]byte{
+				flacBlock(flacVorbisComment, false, vorbisComment("TITLE=T")),
+				flacBlock(flacPicture, true, flacPictureData("image/png", cover)),
+			}, nil)...),
+			want: Tags{Title: "T", MIME: "image/png", Picture: cover},
+		},
+		{
+			name: "flac truncated block keeps earlier tags",
+			file: append([]byte("fLaC"), bytes.Join([][]byte{
+				flacBlock(flacVorbisComment, false, vorbisComment("TITLE=T")),
+				flacBlock(flacPicture, true, flacPictureData("image/png", cover))[:20],
+			}, nil)...),
+			want: Tags{Title: "T"},
+		},
+		{
+			name: "flac comment with bad lengths",
+			file: append([]byte("fLaC"), flacBlock(flacVorbisComment, true,
+				[]byte{4, 0, 0, 0, 't', 'e', 's', 't', 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0, 0})...),
+		},
+		{
+			name: "ogg vorbis",
+			file: bytes.Join([][]byte{
+				oggPage([]byte("\x01vorbis identification")),
+				oggPage(append([]byte("\x03vorbis"), vorbisComment("TITLE=Ogg", "ARTIST=Vorbis")...)),
+			}, nil),
+			want: Tags{Title: "Ogg", Artist: "Vorbis"},
+		},
+		{
+			name: "ogg opus with picture and a packet over 255 bytes",
+			file: oggPage(
+				[]byte("OpusHead"),
+				append([]byte("OpusTags"), vorbisComment(
+					"TITLE=Opus",
+					"METADATA_BLOCK_PICTURE="+base64.StdEncoding.EncodeToString(flacPictureData("image/jpeg", cover)),
+					"PADDING="+string(bytes.Repeat([]byte("p"), 300)),
+				)...),
+			),
+			want: Tags{Title: "Opus", MIME: "image/jpeg", Picture: cover},
+		},
+		{
+			name: "ogg unknown codec",
+			file: oggPage([]byte("\x01other"), append([]byte("\x03other!"), longComment...)),
+		},
+		{
+			name: "ogg truncated page",
+			file: oggPage([]byte("OpusHead"), append([]byte("OpusTags"), longComment...))[:100],
+		},
+		{
+			name: "unknown format",
+			file: []byte("RIFF....WAVE"),
+		},
+	}
+
+	dir := t.TempDir()
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+n)))
+			if err := os.WriteFile(path, tt.file, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got := readTags(path)
+			if got.Title != tt.want.Title || got.Artist != tt.want.Artist || got.Album != tt.want.Album {
+				t.Errorf("got %q / %q / %q, want %q / %q / %q",
+					got.Title, got.Artist, got.Album, tt.want.Title, tt.want.Artist, tt.want.Album)
+			}
+			if got.MIME != tt.want.MIME || !bytes.Equal(got.Picture, tt.want.Picture) {
+				t.Errorf("picture %q %x, want %q %x", got.MIME, got.Picture, tt.want.MIME, tt.want.Picture)
+			}
+		})
+	}
+}
+
+func TestSyncsafe(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want int
+	}{
+		{[]byte{0, 0, 0, 0}, 0},
+		{[]byte{0, 0, 0, 0x7f}, 127},
+		{[]byte{0, 0, 1, 0}, 128},
+		{[]byte{0, 0, 2, 1}, 257},
+		{[]byte{0x7f, 0x7f, 0x7f, 0x7f}, 1<<28 - 1},
+	}
+	for _, tt := range tests {
+		if got := syncsafe(tt.in); got != tt.want {
+			t.Errorf("syncsafe(%x) = %d, want %d", tt.in, got, tt.want)
+		}
+		if got := syncsafe(syncsafeBytes(tt.want)); got != tt.want {
+			t.Errorf("round trip %d = %d", tt.want, got)
+		}
+	}
+}
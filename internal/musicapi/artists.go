@@ -0,0 +1,72 @@
+package musicapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// artistSeparators splits credit strings such as "A, B & C feat. D".
+// Plain "and" is left alone: too many band names contain it.
+var artistSeparators = regexp.MustCompile(`(?i)\s*(?:,|;|\s&\s|\s\(?feat\.?\s|\s\(?ft\.?\s|\s\(?featuring\s)\s*`)
+
+// SplitArtists breaks a credit string into individual names, in order.
+func SplitArtists(s string) []string {
+	var out []string
+	for _, part := range artistSeparators.Split(" "+s+" ", -1) {
+		part = strings.TrimSpace(strings.Trim(strings.TrimSpace(part), "()"))
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// JoinArtists renders names for display: "A", "A & B", "A, B & C".
+func JoinArtists(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " & " + names[len(names)-1]
+}
+
+// PrimaryArtist is the first credited artist, for searches and matching.
+func (s SongLite) PrimaryArtist() string {
+	if len(s.Artists) > 0 {
+		return s.Artists[0]
+	}
+	return strings.TrimSpace(s.Artist)
+}
+
+// HasArtist reports whether name is one of the song's credited artists.
+func (s SongLite) HasArtist(name string) bool {
+	want := normalizeKey(name)
+	if want == "" {
+		return false
+	}
+	for _, a := range s.Artists {
+		if normalizeKey(a) == want {
+			return true
+		}
+	}
+	return len(s.Artists) == 0 && strings.Contains(normalizeKey(s.Artist), want)
+}
+
+// appendArtists adds names not already in list, ignoring case and spacing.
+func appendArtists(list []string, names ...string) []string {
+	for _, name := range names {
+		dup := false
+		for _, have := range list {
+			if normalizeKey(have) == normalizeKey(name) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, name)
+		}
+	}
+	return list
+}
@@ -0,0 +1,185 @@
+package musicapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Backend is one deployment (mirror) of the music API.
+type Backend struct {
+	Base   string
+	Prefix string
+}
+
+type backend struct {
+	Backend
+	breaker *breaker
+}
+
+// SearchMode decides how SearchSongs uses multiple backends.
+type SearchMode int
+
+const (
+	// SearchFailover asks backends in order and returns the first answer.
+	SearchFailover SearchMode = iota
+	// SearchMerge asks every backend at once and merges the results,
+	// dropping songs that several mirrors return.
+	SearchMerge
+)
+
+func ParseSearchMode(s string) (SearchMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "failover":
+		return SearchFailover, nil
+	case "merge":
+		return SearchMerge, nil
+	}
+	return SearchFailover, fmt.Errorf("unknown search mode %q (use failover or merge)", s)
+}
+
+// failover runs fn against the backends listed in order until one succeeds.
+func (c *Client) failover(ctx context.Context, order []int, fn func(idx int, be *backend) error) error {
+	var errs []error
+	for _, idx := range order {
+		err := fn(idx, c.backends[idx])
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		errs = append(errs, err)
+	}
+	return pickError(errs)
+}
+
+// pickError reports the most useful of several backend errors: a real
+// failure beats "circuit open", which beats "not found" (another provider
+// simply not knowing an ID says little).
+func pickError(errs []error) error {
+	if len(errs) == 0 {
+		return errors.New("no music API backends configured")
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if errors.Is(err, ErrCircuitOpen) {
+			return err
+		}
+	}
+	return errs[0]
+}
+
+func (c *Client) searchFailover(ctx context.Context, search func(*backend) ([]SongLite, error)) ([]SongLite, error) {
+	var songs []SongLite
+	err := c.failover(ctx, c.orderFor(""), func(idx int, be *backend) error {
+		res, err := search(be)
+		if err != nil {
+			return err
+		}
+		songs = res
+		c.rememberOrigins(idx, res)
+		return nil
+	})
+	return songs, err
+}
+
+// searchMerged fans out to every backend and merges the answers in backend
+// order, keeping the first copy of each normalized title+artist.
+func (c *Client) searchMerged(ctx context.Context, search func(*backend) ([]SongLite, error)) ([]SongLite, error) {
+	results := make([][]SongLite, len(c.backends))
+	errs := make([]error, len(c.backends))
+
+	var wg sync.WaitGroup
+	for idx, be := range c.backends {
+		wg.Add(1)
+		go func(idx int, be *backend) {
+			defer wg.Done()
+			results[idx], errs[idx] = search(be)
+		}(idx, be)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var merged []SongLite
+	seen := make(map[string]bool)
+	ok := false
+	for idx, res := range results {
+		if errs[idx] != nil {
+			continue
+		}
+		ok = true
+		c.rememberOrigins(idx, res)
+		for _, s := range res {
+			key := SongKey(s)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, s)
+		}
+	}
+	if !ok {
+		return nil, pickError(errs)
+	}
+	return merged, nil
+}
+
+func (c *Client) rememberOrigins(idx int, songs []SongLite) {
+	if len(c.backends) < 2 {
+		return
+	}
+	for _, s := range songs {
+		// first backend to report an ID keeps it
+		if _, ok := c.origins.Get(s.ID); !ok {
+			c.origins.Add(s.ID, idx)
+		}
+	}
+}
+
+// orderFor lists backend indexes to try for id: the one it came from first,
+// then the rest in configured order.
+func (c *Client) orderFor(id string) []int {
+	order := make([]int, 0, len(c.backends))
+	first := -1
+	if id != "" {
+		if idx, ok := c.origins.Get(id); ok && idx < len(c.backends) {
+			first = idx
+			order = append(order, idx)
+		}
+	}
+	for idx := range c.backends {
+		if idx != first {
+			order = append(order, idx)
+		}
+	}
+	return order
+}
+
+// SongKey identifies a song across backends and duplicate IDs: its title
+// and primary artist, lowercased with punctuation and spacing removed. Using
+// only the primary artist keeps a song the same whether or not a backend
+// credits its featured artists.
+func SongKey(s SongLite) string {
+	return normalizeKey(s.Title) + "|" + normalizeKey(s.PrimaryArtist())
+}
+
+func normalizeKey(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
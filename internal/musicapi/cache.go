@@ -0,0 +1,154 @@
+package musicapi
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"musicbot/internal/cache"
+)
+
+// CacheOptions sizes the optional response cache. Search results change
+// often and get a short TTL; song details are close to immutable.
+type CacheOptions struct {
+	SearchSize int
+	SearchTTL  time.Duration
+	SongSize   int
+	SongTTL    time.Duration
+}
+
+// CacheStats reports hit/miss counters for both caches.
+type CacheStats struct {
+	Search cache.Stats `json:"search"`
+	Songs  cache.Stats `json:"songs"`
+}
+
+type responseCache struct {
+	search *cache.LRU[string, []SongLite]
+	songs  *cache.LRU[string, SongDetail]
+}
+
+// EnableCache turns on response caching for SearchSongs and GetSongByID.
+// Call it before the client is shared between goroutines.
+func (c *Client) EnableCache(opts CacheOptions) {
+	c.cache = &responseCache{
+		search: cache.New[string, []SongLite](opts.SearchSize, opts.SearchTTL),
+		songs:  cache.New[string, SongDetail](opts.SongSize, opts.SongTTL),
+	}
+}
+
+// CacheStats returns the cache counters, or false if caching is off.
+func (c *Client) CacheStats() (CacheStats, bool) {
+	if c.cache == nil {
+		return CacheStats{}, false
+	}
+	return CacheStats{
+		Search: c.cache.search.Stats(),
+		Songs:  c.cache.songs.Stats(),
+	}, true
+}
+
+// InvalidateSearch forgets the cached results for query, every page of
+// them included.
+func (c *Client) InvalidateSearch(query string) {
+	if c.cache != nil {
+		key := searchKey(query)
+		c.cache.search.RemoveFunc(func(k string) bool {
+			return k == key || strings.HasPrefix(k, key+pageKeySep)
+		})
+	}
+}
+
+// InvalidateSong forgets the cached details for id.
+func (c *Client) InvalidateSong(id string) {
+	if c.cache != nil {
+		c.cache.songs.Remove(id)
+	}
+}
+
+// PurgeCache empties both caches.
+func (c *Client) PurgeCache() {
+	if c.cache != nil {
+		c.cache.search.Purge()
+		c.cache.songs.Purge()
+	}
+}
+
+// cachedSearch and storeSearch take a key from searchPageKey.
+func (c *Client) cachedSearch(key string) ([]SongLite, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	songs, ok := c.cache.search.Get(key)
+	if !ok {
+		return nil, false
+	}
+	// callers are free to reorder/trim/edit what they get back
+	return cloneSongs(songs), true
+}
+
+func (c *Client) storeSearch(key string, songs []SongLite) {
+	if c.cache != nil {
+		c.cache.search.Add(key, cloneSongs(songs))
+	}
+}
+
+func (c *Client) cachedSong(id string) (*SongDetail, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	d, ok := c.cache.songs.Get(id)
+	if !ok {
+		return nil, false
+	}
+	d = d.clone()
+	return &d, true
+}
+
+func (c *Client) storeSong(id string, d *SongDetail) {
+	if c.cache != nil {
+		c.cache.songs.Add(id, d.clone())
+	}
+}
+
+// Cached entries never share slices with what callers hold: a copy is
+// stored and every hit hands out a fresh one.
+
+func (s SongLite) clone() SongLite {
+	s.Artists = slices.Clone(s.Artists)
+	return s
+}
+
+func (d SongDetail) clone() SongDetail {
+	d.SongLite = d.SongLite.clone()
+	d.Qualities = slices.Clone(d.Qualities)
+	return d
+}
+
+func cloneSongs(songs []SongLite) []SongLite {
+	out := make([]SongLite, len(songs))
+	for n, s := range songs {
+		out[n] = s.clone()
+	}
+	return out
+}
+
+// searchKey normalizes a query so "Tum  Hi Ho" and "tum hi ho" share an entry.
+func searchKey(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// pageKeySep separates a query from its page in search cache keys; it
+// can't appear in a normalized query's words.
+const pageKeySep = "\x00"
+
+// searchPageKey is the cache key of one page of results for query. The
+// unpaged search (page and limit 0) uses the bare searchKey.
+func searchPageKey(query string, page, limit int) string {
+	key := searchKey(query)
+	if page > 0 || limit > 0 {
+		key += pageKeySep + strconv.Itoa(page) + "/" + strconv.Itoa(limit)
+	}
+	return key
+}
@@ -1,72 +1,234 @@
 package musicapi
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"time"
+
+	"musicbot/internal/cache"
 )
 
+// originCacheSize bounds how many song IDs remember their backend.
+const originCacheSize = 4096
+
 type Client struct {
-	Base   string
-	Prefix string
-	http   *http.Client
+	backends []*backend
+	mode     SearchMode
+	origins  *cache.LRU[string, int] // song ID -> index of the backend that returned it
+
+	http    *http.Client
+	cache   *responseCache // nil unless EnableCache was called
+	retry   RetryPolicy
+	mapping *compiledMapping
 }
 
 func New(base, prefix string) *Client {
-	return &Client{
-		Base:   strings.TrimRight(base, "/"),
-		Prefix: "/" + strings.Trim(prefix, "/"),
-		http:   &http.Client{Timeout: 12 * time.Second},
+	return NewMulti([]Backend{{Base: base, Prefix: prefix}}, SearchFailover)
+}
+
+// NewMulti creates a client over an ordered list of API mirrors. Earlier
+// backends are preferred; mode decides how searches use the rest.
+func NewMulti(backends []Backend, mode SearchMode) *Client {
+	c := &Client{
+		mode:    mode,
+		origins: cache.New[string, int](originCacheSize, 0),
+		http:    &http.Client{Timeout: 12 * time.Second},
+		retry:   DefaultRetryPolicy,
+		mapping: defaultCompiled,
+	}
+	for _, b := range backends {
+		c.backends = append(c.backends, &backend{
+			Backend: Backend{
+				Base:   strings.TrimRight(b.Base, "/"),
+				Prefix: "/" + strings.Trim(b.Prefix, "/"),
+			},
+			breaker: newBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
+		})
 	}
+	return c
+}
+
+// SetRetryPolicy replaces DefaultRetryPolicy. Call it before the client is
+// shared between goroutines.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	c.retry = p
+}
+
+// SetCircuitBreaker configures after how many consecutive failed requests
+// the client stops calling a backend, and for how long.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	for _, be := range c.backends {
+		be.breaker = newBreaker(threshold, cooldown)
+	}
+}
+
+// SetMapping replaces DefaultMapping for parsing responses. Sections left
+// empty fall back to the default paths.
+func (c *Client) SetMapping(m Mapping) error {
+	cm, err := m.withDefaults().compile()
+	if err != nil {
+		return err
+	}
+	c.mapping = cm
+	return nil
 }
 
 func (c *Client) SearchSongs(query string) ([]SongLite, error) {
-	u, _ := url.Parse(c.Base)
-	u.Path = path.Join(u.Path, c.Prefix, "/search/songs")
-	q := u.Query()
-	q.Set("query", query)
-	u.RawQuery = q.Encode()
+	return c.SearchSongsContext(context.Background(), query)
+}
+
+// SearchSongsContext is SearchSongs with cancellation: the HTTP request and
+// any retry waits stop as soon as ctx is done.
+func (c *Client) SearchSongsContext(ctx context.Context, query string) ([]SongLite, error) {
+	return c.SearchSongsPage(ctx, query, 0, 0)
+}
+
+// SearchSongsPage asks for one page of results, sending the API's 1-based
+// page and limit parameters. Zero values leave them to the API's defaults.
+// APIs that ignore the parameters return their first page every time.
+func (c *Client) SearchSongsPage(ctx context.Context, query string, page, limit int) ([]SongLite, error) {
+	key := searchPageKey(query, page, limit)
+	if songs, ok := c.cachedSearch(key); ok {
+		return songs, nil
+	}
 
-	raw, err := c.getJSON(u.String())
+	search := func(be *backend) ([]SongLite, error) {
+		u := be.endpoint("/search/songs")
+		q := u.Query()
+		q.Set("query", query)
+		if page > 0 {
+			q.Set("page", strconv.Itoa(page))
+		}
+		if limit > 0 {
+			q.Set("limit", strconv.Itoa(limit))
+		}
+		u.RawQuery = q.Encode()
+
+		raw, err := c.getJSON(ctx, be, u.String())
+		if err != nil {
+			return nil, err
+		}
+		return c.mapping.searchSongs(raw)
+	}
+
+	var songs []SongLite
+	var err error
+	if c.mode == SearchMerge {
+		songs, err = c.searchMerged(ctx, search)
+	} else {
+		songs, err = c.searchFailover(ctx, search)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return NormalizeSearchSongs(raw)
+	c.storeSearch(key, songs)
+	return songs, nil
 }
 
 func (c *Client) GetSongByID(id string) (*SongDetail, error) {
-	u, _ := url.Parse(c.Base)
-	u.Path = path.Join(u.Path, c.Prefix, "/songs", id)
+	return c.GetSongByIDContext(context.Background(), id)
+}
 
-	raw, err := c.getJSON(u.String())
-	if err != nil {
-		return nil, err
+// GetSongByIDContext is GetSongByID with cancellation. The backend that
+// returned id in a search is asked first.
+func (c *Client) GetSongByIDContext(ctx context.Context, id string) (*SongDetail, error) {
+	if d, ok := c.cachedSong(id); ok {
+		return d, nil
 	}
-	d, err := NormalizeSongDetail(raw)
+
+	var d SongDetail
+	err := c.failover(ctx, c.orderFor(id), func(idx int, be *backend) error {
+		u := be.endpoint("/songs", id)
+		raw, err := c.getJSON(ctx, be, u.String())
+		if err != nil {
+			return err
+		}
+		d, err = c.mapping.songDetail(raw)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	c.storeSong(id, &d)
 	return &d, nil
 }
 
-func (c *Client) getJSON(fullURL string) (any, error) {
-	resp, err := c.http.Get(fullURL)
+// getJSON fetches fullURL from be, retrying transient failures per c.retry.
+// A request that still fails counts against the backend's circuit breaker.
+func (c *Client) getJSON(ctx context.Context, be *backend, fullURL string) (any, error) {
+	if err := be.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		v, err := c.fetchJSON(ctx, fullURL)
+		if ctx.Err() != nil {
+			// our caller gave up; that says nothing about the API's health
+			be.breaker.release()
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			be.breaker.success()
+			return v, nil
+		}
+		if !retryable(err) {
+			// the API answered; it's just not an answer we can use
+			be.breaker.success()
+			return nil, err
+		}
+		if attempt+1 >= c.retry.MaxAttempts {
+			be.breaker.failure()
+			return nil, err
+		}
+		wait, ok := c.retry.backoff(attempt, err)
+		if !ok {
+			be.breaker.failure()
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			be.breaker.release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) fetchJSON(ctx context.Context, fullURL string) (any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api status %d", resp.StatusCode)
+		return nil, &StatusError{
+			Code:       resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
 	var v any
 	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
-		return nil, err
+		return nil, &decodeError{err}
 	}
 	return v, nil
 }
+
+func (be *backend) endpoint(parts ...string) *url.URL {
+	u, _ := url.Parse(be.Base)
+	u.Path = path.Join(append([]string{u.Path, be.Prefix}, parts...)...)
+	return u
+}
@@ -0,0 +1,155 @@
+package musicapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// albumOriginPrefix keeps album IDs apart from song IDs in c.origins.
+const albumOriginPrefix = "album:"
+
+// SearchAlbums searches /search/albums. Albums are always looked up with
+// failover, whatever the song search mode.
+func (c *Client) SearchAlbums(ctx context.Context, query string) ([]Collection, error) {
+	var albums []Collection
+	err := c.failover(ctx, c.orderFor(""), func(idx int, be *backend) error {
+		u := be.endpoint("/search/albums")
+		q := u.Query()
+		q.Set("query", query)
+		u.RawQuery = q.Encode()
+
+		raw, err := c.getJSON(ctx, be, u.String())
+		if err != nil {
+			return err
+		}
+		albums, err = c.mapping.searchCollections(raw)
+		if err != nil {
+			return err
+		}
+		if len(albums) == 0 {
+			return ErrNotFound
+		}
+		for _, a := range albums {
+			c.origins.Add(albumOriginPrefix+a.ID, idx)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return albums, err
+}
+
+// GetAlbum fetches /albums/{id} with its tracks.
+func (c *Client) GetAlbum(ctx context.Context, id string) (*Collection, error) {
+	return c.getCollection(ctx, c.orderFor(albumOriginPrefix+id), "/albums", id)
+}
+
+// GetPlaylist fetches /playlists/{id} with its tracks.
+func (c *Client) GetPlaylist(ctx context.Context, id string) (*Collection, error) {
+	return c.getCollection(ctx, c.orderFor(""), "/playlists", id)
+}
+
+// GetArtistSongs fetches /artists/{id}/songs. The collection carries the
+// artist's name when the API includes it.
+func (c *Client) GetArtistSongs(ctx context.Context, id string) (*Collection, error) {
+	return c.getCollection(ctx, c.orderFor(""), "/artists", id, "songs")
+}
+
+func (c *Client) getCollection(ctx context.Context, order []int, parts ...string) (*Collection, error) {
+	var coll Collection
+	err := c.failover(ctx, order, func(idx int, be *backend) error {
+		raw, err := c.getJSON(ctx, be, be.endpoint(parts...).String())
+		if err != nil {
+			return err
+		}
+		coll, err = c.mapping.collection(raw)
+		if err != nil {
+			return err
+		}
+		lites := make([]SongLite, len(coll.Songs))
+		for n, s := range coll.Songs {
+			lites[n] = s.SongLite
+		}
+		c.rememberOrigins(idx, lites)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &coll, nil
+}
+
+// --- normalizers ---
+
+func (m *compiledMapping) searchCollections(raw any) ([]Collection, error) {
+	v, ok := firstMatch(raw, m.results, isArray)
+	if !ok {
+		return nil, errors.New("could not parse album search response (JSON shape not recognized)")
+	}
+	var out []Collection
+	for _, item := range v.([]any) {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if coll := m.collectionFromObj(obj); coll.ID != "" && coll.Title != "" {
+			out = append(out, coll)
+		}
+	}
+	return out, nil
+}
+
+// collection reads a collection and its tracks. Responses that are just a
+// list of songs (artist catalogues) give a collection without metadata.
+func (m *compiledMapping) collection(raw any) (Collection, error) {
+	var coll Collection
+	if v, ok := firstMatch(raw, m.coll.detail, isObject); ok {
+		coll = m.collectionFromObj(v.(map[string]any))
+	}
+
+	v, ok := firstMatch(raw, m.coll.songs, isArray)
+	if !ok {
+		return Collection{}, errors.New("could not parse collection (no song list found)")
+	}
+	for _, item := range v.([]any) {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		d := m.detailFromObj(obj)
+		d.sanitize()
+		// album tracks often leave the artist and cover to the album
+		if d.Artist == "" && coll.Artist != "" {
+			d.Artists = SplitArtists(coll.Artist)
+			d.Artist = JoinArtists(d.Artists)
+		}
+		if d.Image == "" {
+			d.Image = coll.Image
+		}
+		if d.ID != "" && d.Title != "" {
+			coll.Songs = append(coll.Songs, d)
+		}
+	}
+	coll.SongCount = len(coll.Songs)
+	return coll, nil
+}
+
+func (m *compiledMapping) collectionFromObj(obj map[string]any) Collection {
+	count, _ := strconv.Atoi(firstText(obj, m.coll.count))
+	coll := Collection{
+		ID:        firstText(obj, m.coll.id),
+		Title:     firstText(obj, m.coll.title),
+		Artist:    JoinArtists(SplitArtists(cleanArtist(CleanText(firstText(obj, m.coll.artist))))),
+		Image:     firstText(obj, m.coll.image),
+		Link:      firstText(obj, m.coll.link),
+		Year:      parseYear(firstText(obj, m.coll.year)),
+		SongCount: count,
+	}
+	coll.ID = CleanText(coll.ID)
+	coll.Title = CleanText(coll.Title)
+	coll.Image = cleanURL(coll.Image)
+	coll.Link = cleanURL(coll.Link)
+	return coll
+}
@@ -0,0 +1,516 @@
+package musicapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Mapping describes where song data lives in API responses, so a new API
+// shape only needs a mapping file instead of code. Every entry is a list of
+// paths tried in order; the first one that yields a value wins.
+//
+// Path syntax: dot-separated object keys, where a key may be followed by an
+// array selector:
+//
+//	data.results      object keys
+//	artists[].name    first element with a non-empty name
+//	image[-1].url     element by index (negative counts from the end)
+//	downloadUrl[best].url
+//	                  the highest bitrate element (see bestIndex)
+//
+// The empty path "" is the value itself. For list fields (artists, featured) every
+// element matched by [] is collected, from the first path that matches any.
+type Mapping struct {
+	Results []string      `json:"results"` // search: the array of songs
+	Detail  []string      `json:"detail"`  // song lookup: the song object
+	Fields  FieldMappings `json:"fields"`
+
+	// Collection covers albums, playlists and artist catalogues. Album
+	// searches find their list with Results.
+	Collection CollectionMappings `json:"collection"`
+}
+
+// CollectionMappings locates an album or playlist and its tracks. Detail
+// and Songs are relative to the response; the rest to the collection
+// object. Tracks are read with FieldMappings.
+type CollectionMappings struct {
+	Detail []string `json:"detail"`
+	Songs  []string `json:"songs"`
+	ID     []string `json:"id"`
+	Title  []string `json:"title"`
+	Artist []string `json:"artist"`
+	Image  []string `json:"image"`
+	Link   []string `json:"link"`
+	Year   []string `json:"year"`
+	Count  []string `json:"count"` // number of tracks, when listed without them
+}
+
+// FieldMappings lists the paths for each song field, relative to one song
+// object. The fields after Stream are only read from song lookups.
+type FieldMappings struct {
+	ID     []string `json:"id"`
+	Title  []string `json:"title"`
+	Artist []string `json:"artist"`
+	Image  []string `json:"image"`
+	Link   []string `json:"link"`
+	Stream []string `json:"stream"`
+
+	// Artists and Featured point at artist lists; every name they yield is
+	// also split with SplitArtists. Artist is the fallback credit string.
+	Artists  []string `json:"artists"`
+	Featured []string `json:"featured"`
+
+	Duration   []string `json:"duration"`    // seconds, or "m:ss"
+	DurationMS []string `json:"duration_ms"` // milliseconds
+	Album      []string `json:"album"`
+	Year       []string `json:"year"` // a year or a date starting with one
+	Language   []string `json:"language"`
+	Explicit   []string `json:"explicit"`
+
+	// Qualities point at arrays of stream variants; QualityLabel and
+	// QualityURL are read from each element.
+	Qualities    []string `json:"qualities"`
+	QualityLabel []string `json:"quality_label"`
+	QualityURL   []string `json:"quality_url"`
+}
+
+// DefaultMapping covers the response shapes the bot has met so far.
+var DefaultMapping = Mapping{
+	Results: []string{"", "data", "data.results", "results"},
+	Detail:  []string{"data", "data[0]", ""},
+	Fields: FieldMappings{
+		ID:    []string{"id", "song_id", "_id"},
+		Title: []string{"title", "name", "song_name"},
+		Artist: []string{
+			"artist", "artists", "primaryArtists", "primary_artists",
+			"subtitle", // often "Artist • Album"
+			"song_artist",
+			"artists[].name",
+			"artists.primary[].name",
+			"artists.all[].name",
+			"primaryArtists[].name",
+		},
+		Artists: []string{
+			"artists.primary[].name",
+			"artists[].name",
+			"primaryArtists[].name",
+		},
+		Featured: []string{
+			"artists.featured[].name",
+			"featuredArtists[].name",
+			"featured_artists[].name",
+			"featuredArtists",
+			"featured_artists",
+		},
+		Image: []string{"image", "thumbnail", "cover", "image[best].url"},
+		Link:  []string{"link", "url", "perma_url"},
+		Stream: []string{
+			"stream", "stream_url", "audio", "audio_url", "download_url", "downloadUrl",
+			"downloadUrl[best].url", "download_url[best].url",
+		},
+		Duration:     []string{"duration", "length", "duration_seconds"},
+		DurationMS:   []string{"duration_ms", "durationMs"},
+		Album:        []string{"album.name", "album.title", "album", "album_name", "more_info.album"},
+		Year:         []string{"year", "release_year", "releaseDate", "release_date"},
+		Language:     []string{"language", "lang"},
+		Explicit:     []string{"explicitContent", "explicit_content", "explicit"},
+		Qualities:    []string{"downloadUrl", "download_url"},
+		QualityLabel: []string{"quality", "label", "bitrate"},
+		QualityURL:   []string{"url", "link"},
+	},
+	Collection: CollectionMappings{
+		// not "data[0]": for artist catalogues that is the first song
+		Detail: []string{"data", ""},
+		Songs:  []string{"data.songs", "songs", "data.results", "results", "data", ""},
+		ID:     []string{"id", "album_id", "listid", "_id"},
+		Title:  []string{"name", "title", "listname"},
+		Artist: []string{
+			"primaryArtists", "artist", "artists", "subtitle",
+			"artists.primary[].name", "artists[].name", "primaryArtists[].name",
+		},
+		Image: []string{"image", "cover", "thumbnail", "image[best].url"},
+		Link:  []string{"url", "link", "perma_url"},
+		Year:  []string{"year", "release_year", "releaseDate"},
+		Count: []string{"songCount", "song_count", "list_count", "count"},
+	},
+}
+
+// LoadMapping reads a JSON mapping file. Sections left out of the file keep
+// their DefaultMapping paths.
+func LoadMapping(path string) (Mapping, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, err
+	}
+	var m Mapping
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return Mapping{}, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	m = m.withDefaults()
+	if _, err := m.compile(); err != nil {
+		return Mapping{}, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m Mapping) withDefaults() Mapping {
+	d := DefaultMapping
+	or := func(v, def []string) []string {
+		if len(v) == 0 {
+			return def
+		}
+		return v
+	}
+	m.Results = or(m.Results, d.Results)
+	m.Detail = or(m.Detail, d.Detail)
+	m.Fields.ID = or(m.Fields.ID, d.Fields.ID)
+	m.Fields.Title = or(m.Fields.Title, d.Fields.Title)
+	m.Fields.Artist = or(m.Fields.Artist, d.Fields.Artist)
+	m.Fields.Image = or(m.Fields.Image, d.Fields.Image)
+	m.Fields.Link = or(m.Fields.Link, d.Fields.Link)
+	m.Fields.Stream = or(m.Fields.Stream, d.Fields.Stream)
+	m.Fields.Artists = or(m.Fields.Artists, d.Fields.Artists)
+	m.Fields.Featured = or(m.Fields.Featured, d.Fields.Featured)
+	m.Fields.Duration = or(m.Fields.Duration, d.Fields.Duration)
+	m.Fields.DurationMS = or(m.Fields.DurationMS, d.Fields.DurationMS)
+	m.Fields.Album = or(m.Fields.Album, d.Fields.Album)
+	m.Fields.Year = or(m.Fields.Year, d.Fields.Year)
+	m.Fields.Language = or(m.Fields.Language, d.Fields.Language)
+	m.Fields.Explicit = or(m.Fields.Explicit, d.Fields.Explicit)
+	m.Fields.Qualities = or(m.Fields.Qualities, d.Fields.Qualities)
+	m.Fields.QualityLabel = or(m.Fields.QualityLabel, d.Fields.QualityLabel)
+	m.Fields.QualityURL = or(m.Fields.QualityURL, d.Fields.QualityURL)
+
+	c, dc := &m.Collection, d.Collection
+	c.Detail = or(c.Detail, dc.Detail)
+	c.Songs = or(c.Songs, dc.Songs)
+	c.ID = or(c.ID, dc.ID)
+	c.Title = or(c.Title, dc.Title)
+	c.Artist = or(c.Artist, dc.Artist)
+	c.Image = or(c.Image, dc.Image)
+	c.Link = or(c.Link, dc.Link)
+	c.Year = or(c.Year, dc.Year)
+	c.Count = or(c.Count, dc.Count)
+	return m
+}
+
+// --- compiled form ---
+
+type compiledMapping struct {
+	results, detail                        []jsonPath
+	id, title, artist, image, link, stream []jsonPath
+	artists, featured                      []jsonPath
+
+	duration, durationMS, album, year, language, explicit []jsonPath
+	qualities, qualityLabel, qualityURL                   []jsonPath
+
+	coll compiledCollection
+}
+
+type compiledCollection struct {
+	detail, songs                               []jsonPath
+	id, title, artist, image, link, year, count []jsonPath
+}
+
+func (m Mapping) compile() (*compiledMapping, error) {
+	var firstErr error
+	c := func(paths []string) []jsonPath {
+		out := make([]jsonPath, 0, len(paths))
+		for _, p := range paths {
+			jp, err := parsePath(p)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			out = append(out, jp)
+		}
+		return out
+	}
+	cm := &compiledMapping{
+		results:  c(m.Results),
+		detail:   c(m.Detail),
+		id:       c(m.Fields.ID),
+		title:    c(m.Fields.Title),
+		artist:   c(m.Fields.Artist),
+		image:    c(m.Fields.Image),
+		link:     c(m.Fields.Link),
+		stream:   c(m.Fields.Stream),
+		artists:  c(m.Fields.Artists),
+		featured: c(m.Fields.Featured),
+
+		duration:     c(m.Fields.Duration),
+		durationMS:   c(m.Fields.DurationMS),
+		album:        c(m.Fields.Album),
+		year:         c(m.Fields.Year),
+		language:     c(m.Fields.Language),
+		explicit:     c(m.Fields.Explicit),
+		qualities:    c(m.Fields.Qualities),
+		qualityLabel: c(m.Fields.QualityLabel),
+		qualityURL:   c(m.Fields.QualityURL),
+
+		coll: compiledCollection{
+			detail: c(m.Collection.Detail),
+			songs:  c(m.Collection.Songs),
+			id:     c(m.Collection.ID),
+			title:  c(m.Collection.Title),
+			artist: c(m.Collection.Artist),
+			image:  c(m.Collection.Image),
+			link:   c(m.Collection.Link),
+			year:   c(m.Collection.Year),
+			count:  c(m.Collection.Count),
+		},
+	}
+	return cm, firstErr
+}
+
+var defaultCompiled = mustCompile(DefaultMapping)
+
+func mustCompile(m Mapping) *compiledMapping {
+	cm, err := m.compile()
+	if err != nil {
+		panic(err)
+	}
+	return cm
+}
+
+// --- paths ---
+
+type selector int
+
+const (
+	selNone  selector = iota
+	selFirst          // []
+	selBest           // [best]
+	selIndex          // [n]
+)
+
+type pathStep struct {
+	key   string // "" to stay on the current value
+	sel   selector
+	index int
+}
+
+type jsonPath []pathStep
+
+func parsePath(p string) (jsonPath, error) {
+	if p == "" {
+		return nil, nil
+	}
+	var out jsonPath
+	for _, seg := range strings.Split(p, ".") {
+		step := pathStep{key: seg}
+		if i := strings.IndexByte(seg, '['); i >= 0 {
+			if !strings.HasSuffix(seg, "]") {
+				return nil, fmt.Errorf("bad path %q: unclosed [", p)
+			}
+			step.key = seg[:i]
+			switch inner := seg[i+1 : len(seg)-1]; inner {
+			case "":
+				step.sel = selFirst
+			case "best":
+				step.sel = selBest
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("bad path %q: selector [%s]", p, inner)
+				}
+				step.sel, step.index = selIndex, n
+			}
+		} else if seg == "" {
+			return nil, fmt.Errorf("bad path %q: empty key", p)
+		}
+		out = append(out, step)
+	}
+	return out, nil
+}
+
+// eval walks path from v and returns the first value accepted by ok.
+func (jp jsonPath) eval(v any, ok func(any) bool) (any, bool) {
+	if len(jp) == 0 {
+		return v, ok(v)
+	}
+	step, rest := jp[0], jp[1:]
+
+	if step.key != "" {
+		obj, isObj := v.(map[string]any)
+		if !isObj {
+			return nil, false
+		}
+		if v, isObj = obj[step.key]; !isObj {
+			return nil, false
+		}
+	}
+	if step.sel == selNone {
+		return rest.eval(v, ok)
+	}
+
+	arr, isArr := v.([]any)
+	if !isArr {
+		return nil, false
+	}
+	switch step.sel {
+	case selIndex:
+		i := step.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return nil, false
+		}
+		return rest.eval(arr[i], ok)
+	case selBest:
+		if i := bestIndex(arr, rest, ok); i >= 0 {
+			return rest.eval(arr[i], ok)
+		}
+		return nil, false
+	default:
+		for _, el := range arr {
+			if out, found := rest.eval(el, ok); found {
+				return out, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// all is eval for list fields: [] visits every element instead of stopping
+// at the first match.
+func (jp jsonPath) all(v any, ok func(any) bool) []any {
+	if len(jp) == 0 {
+		if ok(v) {
+			return []any{v}
+		}
+		return nil
+	}
+	step, rest := jp[0], jp[1:]
+	if step.sel != selFirst {
+		// only [] fans out; other steps behave as in eval
+		head := jsonPath{step}
+		next, found := head.eval(v, func(any) bool { return true })
+		if !found {
+			return nil
+		}
+		return rest.all(next, ok)
+	}
+	if step.key != "" {
+		obj, isObj := v.(map[string]any)
+		if !isObj {
+			return nil
+		}
+		v = obj[step.key]
+	}
+	arr, _ := v.([]any)
+	var out []any
+	for _, el := range arr {
+		out = append(out, rest.all(el, ok)...)
+	}
+	return out
+}
+
+// bestIndex picks the element [best] refers to: the usable one with the
+// highest bitrate in its quality label, or the last usable one when no
+// labels parse (APIs list qualities from lowest to highest).
+func bestIndex(arr []any, rest jsonPath, ok func(any) bool) int {
+	best, bestRate := -1, -1
+	for i, el := range arr {
+		if _, found := rest.eval(el, ok); !found {
+			continue
+		}
+		if rate := elementBitrate(el); rate >= bestRate {
+			best, bestRate = i, rate
+		}
+	}
+	return best
+}
+
+// qualityKeys are where [best] looks for a quality label in each element.
+var qualityKeys = []string{"quality", "bitrate", "label"}
+
+func elementBitrate(el any) int {
+	obj, ok := el.(map[string]any)
+	if !ok {
+		return 0
+	}
+	for _, k := range qualityKeys {
+		if rate := parseBitrate(scalarString(obj[k])); rate > 0 {
+			return rate
+		}
+	}
+	return 0
+}
+
+func isArray(v any) bool  { _, ok := v.([]any); return ok }
+func isObject(v any) bool { _, ok := v.(map[string]any); return ok }
+
+func isNonEmptyScalar(v any) bool {
+	return scalarString(v) != ""
+}
+
+// scalarString returns v as trimmed text for strings and numbers (numeric
+// IDs are common), or "" for anything else.
+func scalarString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return ""
+}
+
+// firstMatch tries paths in order and returns the first accepted value.
+func firstMatch(v any, paths []jsonPath, ok func(any) bool) (any, bool) {
+	for _, p := range paths {
+		if out, found := p.eval(v, ok); found {
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+func firstText(obj map[string]any, paths []jsonPath) string {
+	v, _ := firstMatch(obj, paths, isNonEmptyScalar)
+	return scalarString(v)
+}
+
+// allText returns every value of the first path that yields any.
+func allText(obj map[string]any, paths []jsonPath) []string {
+	for _, p := range paths {
+		var out []string
+		for _, v := range p.all(obj, isNonEmptyScalar) {
+			out = append(out, scalarString(v))
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+	return nil
+}
+
+func isFlag(v any) bool {
+	switch v.(type) {
+	case bool, float64, string:
+		return true
+	}
+	return false
+}
+
+// firstFlag reads a boolean that APIs spell as true, 1 or "true".
+func firstFlag(obj map[string]any, paths []jsonPath) bool {
+	v, _ := firstMatch(obj, paths, isFlag)
+	switch t := v.(type) {
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(t))
+		return b
+	}
+	return false
+}
@@ -1,12 +1,46 @@
 package musicapi
 
+import "time"
+
 type SongLite struct {
 	ID        string
 	Title     string
-	Artist    string
+	Artist    string   // display credit, JoinArtists(Artists) when known
+	Artists   []string // primary artists first, then featured
 	Image     string
 	Link      string
 	StreamURL string // direct audio stream if your API provides it
 }
 
-type SongDetail = SongLite
+// SongDetail is what a song lookup returns: the search fields plus
+// everything the API knows about the track. Zero values mean unknown.
+type SongDetail struct {
+	SongLite
+
+	Duration  time.Duration
+	Album     string
+	Year      int
+	Language  string
+	Explicit  bool
+	Qualities []StreamQuality // best first; see StreamCandidates
+}
+
+// StreamQuality is one of the stream URLs a song is available in.
+type StreamQuality struct {
+	Label   string // e.g. "320kbps"
+	Bitrate int    // kbps parsed from Label, 0 if unknown
+	URL     string
+}
+
+// Collection is an album, a playlist or an artist's songs. In search
+// results Songs is empty and SongCount says how many there are.
+type Collection struct {
+	ID        string
+	Title     string
+	Artist    string
+	Image     string
+	Link      string
+	Year      int
+	SongCount int
+	Songs     []SongDetail
+}
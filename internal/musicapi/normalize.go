@@ -3,59 +3,50 @@ package musicapi
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// NormalizeSearchSongs extracts songs from a search response using
+// DefaultMapping.
 func NormalizeSearchSongs(raw any) ([]SongLite, error) {
-	switch t := raw.(type) {
-	case []any:
-		return songsFromArray(t), nil
-	case map[string]any:
-		if data, ok := t["data"]; ok {
-			switch d := data.(type) {
-			case []any:
-				return songsFromArray(d), nil
-			case map[string]any:
-				if res, ok := d["results"].([]any); ok {
-					return songsFromArray(res), nil
-				}
-			}
-		}
-		if res, ok := t["results"].([]any); ok {
-			return songsFromArray(res), nil
-		}
-	}
-	return nil, errors.New("could not parse search response (JSON shape not recognized)")
+	return defaultCompiled.searchSongs(raw)
 }
 
+// NormalizeSongDetail extracts a song from a detail response using
+// DefaultMapping.
 func NormalizeSongDetail(raw any) (SongDetail, error) {
-	switch t := raw.(type) {
-	case map[string]any:
-		if data, ok := t["data"]; ok {
-			switch d := data.(type) {
-			case map[string]any:
-				return songFromObj(d), nil
-			case []any:
-				if len(d) > 0 {
-					if obj, ok := d[0].(map[string]any); ok {
-						return songFromObj(obj), nil
-					}
-				}
-			}
-		}
-		return songFromObj(t), nil
+	return defaultCompiled.songDetail(raw)
+}
+
+func (m *compiledMapping) searchSongs(raw any) ([]SongLite, error) {
+	v, ok := firstMatch(raw, m.results, isArray)
+	if !ok {
+		return nil, errors.New("could not parse search response (JSON shape not recognized)")
+	}
+	return m.songsFromArray(v.([]any)), nil
+}
+
+func (m *compiledMapping) songDetail(raw any) (SongDetail, error) {
+	v, ok := firstMatch(raw, m.detail, isObject)
+	if !ok {
+		return SongDetail{}, fmt.Errorf("could not parse song detail")
 	}
-	return SongDetail{}, fmt.Errorf("could not parse song detail")
+	d := m.detailFromObj(v.(map[string]any))
+	d.sanitize()
+	return d, nil
 }
 
-func songsFromArray(arr []any) []SongLite {
+func (m *compiledMapping) songsFromArray(arr []any) []SongLite {
 	out := make([]SongLite, 0, len(arr))
 	for _, item := range arr {
 		obj, ok := item.(map[string]any)
 		if !ok {
 			continue
 		}
-		s := songFromObj(obj)
+		s := m.songFromObj(obj)
+		s.sanitize()
 		if s.ID != "" && s.Title != "" {
 			out = append(out, s)
 		}
@@ -63,131 +54,112 @@ func songsFromArray(arr []any) []SongLite {
 	return out
 }
 
-func songFromObj(obj map[string]any) SongLite {
-	// Basic fields
-	id := firstString(obj, "id", "song_id", "_id")
-	title := firstString(obj, "title", "name", "song_name")
-
-	// ✅ Artist: support many shapes so it doesn't become Unknown Artist
-	artist := firstString(obj,
-		"artist",
-		"artists",
-		"primaryArtists",
-		"primary_artists",
-		"subtitle", // often "Artist • Album"
-		"song_artist",
-	)
-	if artist == "" {
-		artist = nestedArtist(obj)
-	}
-	artist = cleanArtist(artist)
-
-	link := firstString(obj, "link", "url", "perma_url")
-	image := firstString(obj, "image", "thumbnail", "cover")
-
-	// Audio / stream URL candidates
-	stream := firstString(obj, "stream", "stream_url", "audio", "audio_url", "download_url", "downloadUrl")
-
-	// image sometimes array: [{quality,url}]
-	if image == "" {
-		if arr, ok := obj["image"].([]any); ok {
-			image = bestUrlFromQualityArray(arr)
-		}
-	}
-
-	// stream sometimes array: downloadUrl: [{quality,url}]
-	if stream == "" {
-		if arr, ok := obj["downloadUrl"].([]any); ok {
-			stream = bestUrlFromQualityArray(arr)
-		}
-		if arr, ok := obj["download_url"].([]any); ok {
-			stream = bestUrlFromQualityArray(arr)
-		}
-	}
-
+func (m *compiledMapping) songFromObj(obj map[string]any) SongLite {
+	artists := m.artistsFromObj(obj)
 	return SongLite{
-		ID:        id,
-		Title:     title,
-		Artist:    artist,
-		Image:     image,
-		Link:      link,
-		StreamURL: stream,
+		ID:        firstText(obj, m.id),
+		Title:     firstText(obj, m.title),
+		Artist:    JoinArtists(artists),
+		Artists:   artists,
+		Image:     firstText(obj, m.image),
+		Link:      firstText(obj, m.link),
+		StreamURL: firstText(obj, m.stream),
 	}
 }
 
-func firstString(obj map[string]any, keys ...string) string {
-	for _, k := range keys {
-		if v, ok := obj[k]; ok {
-			if s, ok := v.(string); ok {
-				return s
-			}
+func (m *compiledMapping) detailFromObj(obj map[string]any) SongDetail {
+	d := SongDetail{
+		SongLite: m.songFromObj(obj),
+		Album:    firstText(obj, m.album),
+		Year:     parseYear(firstText(obj, m.year)),
+		Language: firstText(obj, m.language),
+		Explicit: firstFlag(obj, m.explicit),
+	}
+	if d.Duration = parseDuration(firstText(obj, m.duration)); d.Duration == 0 {
+		if ms, err := strconv.ParseFloat(firstText(obj, m.durationMS), 64); err == nil && ms > 0 {
+			d.Duration = time.Duration(ms * float64(time.Millisecond))
 		}
 	}
-	return ""
+	d.Qualities = m.qualitiesFromObj(obj)
+	return d
 }
 
-func bestUrlFromQualityArray(arr []any) string {
-	var last string
-	for _, it := range arr {
-		obj, ok := it.(map[string]any)
+func (m *compiledMapping) qualitiesFromObj(obj map[string]any) []StreamQuality {
+	v, ok := firstMatch(obj, m.qualities, isArray)
+	if !ok {
+		return nil
+	}
+	var out []StreamQuality
+	for _, el := range v.([]any) {
+		q, ok := el.(map[string]any)
 		if !ok {
 			continue
 		}
-		if u, ok := obj["url"].(string); ok && u != "" {
-			last = u
+		if u := firstText(q, m.qualityURL); u != "" {
+			label := firstText(q, m.qualityLabel)
+			out = append(out, StreamQuality{Label: label, Bitrate: parseBitrate(label), URL: u})
 		}
 	}
-	return last
+	rankQualities(out)
+	return out
 }
 
-// --- Artist helpers ---
-
-func nestedArtist(obj map[string]any) string {
-	// artists: [{name:"..."}]
-	if arr, ok := obj["artists"].([]any); ok {
-		for _, it := range arr {
-			if m, ok := it.(map[string]any); ok {
-				if name, ok := m["name"].(string); ok && strings.TrimSpace(name) != "" {
-					return strings.TrimSpace(name)
-				}
-			}
-		}
+// parseDuration accepts plain seconds ("245", "245.6") or clock notation
+// ("4:05", "1:02:03"). Anything else is unknown (0).
+func parseDuration(s string) time.Duration {
+	if s == "" {
+		return 0
 	}
-
-	// artists: { primary: [{name:""}] } OR { all: [...] }
-	if m, ok := obj["artists"].(map[string]any); ok {
-		if prim, ok := m["primary"].([]any); ok {
-			for _, it := range prim {
-				if mm, ok := it.(map[string]any); ok {
-					if name, ok := mm["name"].(string); ok && strings.TrimSpace(name) != "" {
-						return strings.TrimSpace(name)
-					}
-				}
-			}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if secs <= 0 {
+			return 0
 		}
-		if all, ok := m["all"].([]any); ok {
-			for _, it := range all {
-				if mm, ok := it.(map[string]any); ok {
-					if name, ok := mm["name"].(string); ok && strings.TrimSpace(name) != "" {
-						return strings.TrimSpace(name)
-					}
-				}
-			}
+		return time.Duration(secs * float64(time.Second))
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	var total int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0
 		}
+		total = total*60 + n
 	}
+	return time.Duration(total) * time.Second
+}
 
-	// primaryArtists might be an array sometimes
-	if arr, ok := obj["primaryArtists"].([]any); ok {
-		for _, it := range arr {
-			if m, ok := it.(map[string]any); ok {
-				if name, ok := m["name"].(string); ok && strings.TrimSpace(name) != "" {
-					return strings.TrimSpace(name)
-				}
-			}
-		}
+// parseYear takes the leading four digits of a year or an ISO date.
+func parseYear(s string) int {
+	if len(s) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(s[:4])
+	if err != nil || y < 1000 {
+		return 0
 	}
+	return y
+}
+
+// --- Artist helpers ---
 
-	return ""
+// artistsFromObj collects primary artists (from a list, else the credit
+// string) followed by featured ones, splitting combined credits. Names are
+// cleaned first so an encoded "&amp;" splits like "&".
+func (m *compiledMapping) artistsFromObj(obj map[string]any) []string {
+	var artists []string
+	for _, a := range allText(obj, m.artists) {
+		artists = appendArtists(artists, SplitArtists(CleanText(a))...)
+	}
+	if len(artists) == 0 {
+		artists = appendArtists(artists, SplitArtists(cleanArtist(CleanText(firstText(obj, m.artist))))...)
+	}
+	for _, a := range allText(obj, m.featured) {
+		artists = appendArtists(artists, SplitArtists(CleanText(a))...)
+	}
+	return artists
 }
 
 func cleanArtist(s string) string {
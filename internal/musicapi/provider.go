@@ -0,0 +1,126 @@
+package musicapi
+
+import (
+	"context"
+	"errors"
+)
+
+// Provider is a source of songs the bot can search and play. The HTTP
+// Client is one implementation; internal/library serves local files.
+type Provider interface {
+	Search(ctx context.Context, query string) ([]SongLite, error)
+	Resolve(ctx context.Context, id string) (*SongDetail, error)
+}
+
+// RelatedProvider is implemented by providers that can suggest tracks to
+// follow a given one (used by autoplay).
+type RelatedProvider interface {
+	Related(ctx context.Context, d *SongDetail) ([]SongLite, error)
+}
+
+// PagedProvider is implemented by providers that can return search results
+// a page at a time. page is 1-based.
+type PagedProvider interface {
+	SearchPage(ctx context.Context, query string, page, limit int) ([]SongLite, error)
+}
+
+// ErrNotFound is returned by providers that don't know an ID.
+var ErrNotFound = errors.New("song not found")
+
+func (c *Client) Search(ctx context.Context, query string) ([]SongLite, error) {
+	return c.SearchSongsContext(ctx, query)
+}
+
+func (c *Client) SearchPage(ctx context.Context, query string, page, limit int) ([]SongLite, error) {
+	return c.SearchSongsPage(ctx, query, page, limit)
+}
+
+func (c *Client) Resolve(ctx context.Context, id string) (*SongDetail, error) {
+	return c.GetSongByIDContext(ctx, id)
+}
+
+// Related searches for more songs by the same artist.
+func (c *Client) Related(ctx context.Context, d *SongDetail) ([]SongLite, error) {
+	artist := d.PrimaryArtist()
+	if artist == "" {
+		return nil, nil
+	}
+	return c.SearchSongsContext(ctx, artist)
+}
+
+// Chain combines providers in priority order: searches concatenate every
+// provider's results, and IDs resolve with the first provider that knows them.
+type Chain []Provider
+
+func (ch Chain) Search(ctx context.Context, query string) ([]SongLite, error) {
+	var out []SongLite
+	var errs []error
+	for _, p := range ch {
+		songs, err := p.Search(ctx, query)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, songs...)
+	}
+	if len(out) == 0 && len(errs) > 0 {
+		return nil, pickError(errs)
+	}
+	return out, nil
+}
+
+// SearchPage pages through providers that support it. Those that don't
+// contribute their whole result list to the first page only.
+func (ch Chain) SearchPage(ctx context.Context, query string, page, limit int) ([]SongLite, error) {
+	var out []SongLite
+	var errs []error
+	for _, p := range ch {
+		var songs []SongLite
+		var err error
+		if pp, ok := p.(PagedProvider); ok {
+			songs, err = pp.SearchPage(ctx, query, page, limit)
+		} else if page <= 1 {
+			songs, err = p.Search(ctx, query)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, songs...)
+	}
+	if len(out) == 0 && len(errs) > 0 {
+		return nil, pickError(errs)
+	}
+	return out, nil
+}
+
+func (ch Chain) Resolve(ctx context.Context, id string) (*SongDetail, error) {
+	var errs []error
+	for _, p := range ch {
+		d, err := p.Resolve(ctx, id)
+		if err == nil {
+			return d, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, err)
+	}
+	return nil, pickError(errs)
+}
+
+func (ch Chain) Related(ctx context.Context, d *SongDetail) ([]SongLite, error) {
+	var out []SongLite
+	for _, p := range ch {
+		rp, ok := p.(RelatedProvider)
+		if !ok {
+			continue
+		}
+		songs, err := rp.Related(ctx, d)
+		if err != nil {
+			continue
+		}
+		out = append(out, songs...)
+	}
+	return out, nil
+}
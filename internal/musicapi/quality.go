@@ -0,0 +1,72 @@
+package musicapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseBitrate reads a kbps figure out of a quality label such as "320kbps",
+// "96 kbps", "160" or "1.4Mbps". It returns 0 when the label has no number.
+func parseBitrate(label string) int {
+	s := strings.ToLower(strings.TrimSpace(label))
+	end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	if end < 0 {
+		end = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	if strings.HasPrefix(strings.TrimSpace(s[end:]), "m") {
+		n *= 1000
+	}
+	return int(n)
+}
+
+// rankQualities sorts qualities best first. Variants without a readable
+// bitrate go last, keeping the API order among themselves.
+func rankQualities(qs []StreamQuality) {
+	sort.SliceStable(qs, func(i, j int) bool {
+		a, b := qs[i].Bitrate, qs[j].Bitrate
+		if a == 0 || b == 0 {
+			return a != 0
+		}
+		return a > b
+	})
+}
+
+// StreamCandidates lists the URLs to try for playback, in order. With
+// maxKbps > 0 the best quality at or under the cap comes first, then the
+// lower ones; qualities over the cap are kept as a last resort, lowest
+// first. StreamURL and Link follow for APIs that don't list qualities.
+func (d *SongDetail) StreamCandidates(maxKbps int) []string {
+	var under, unknown, over []string
+	for _, q := range d.Qualities { // already best first
+		switch {
+		case q.Bitrate == 0:
+			unknown = append(unknown, q.URL)
+		case maxKbps > 0 && q.Bitrate > maxKbps:
+			over = append([]string{q.URL}, over...)
+		default:
+			under = append(under, q.URL)
+		}
+	}
+
+	var out []string
+	seen := make(map[string]bool)
+	add := func(urls ...string) {
+		for _, u := range urls {
+			if u != "" && !seen[u] {
+				seen[u] = true
+				out = append(out, u)
+			}
+		}
+	}
+	add(under...)
+	add(unknown...)
+	add(over...)
+	add(d.StreamURL, d.Link)
+	return out
+}
@@ -0,0 +1,165 @@
+package musicapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Search ranking weights. Title matches count more than artist matches, and
+// a playable stream breaks ties between otherwise similar results.
+const (
+	scoreExactTitle  = 100
+	scoreTitlePrefix = 40
+	scoreAllTerms    = 30
+	scoreTitleTerm   = 12
+	scoreArtistTerm  = 8
+	scoreFuzzyTerm   = 4
+	scoreHasStream   = 15
+)
+
+// RankSongs orders search results by how well they match query and drops
+// duplicates: the same ID, or the same song (see SongKey) under another ID.
+// Of each group of duplicates the best scoring entry is kept. Results that
+// score the same keep the API's order.
+func RankSongs(query string, songs []SongLite) []SongLite {
+	terms := searchTerms(query)
+	whole := normalizeKey(query)
+
+	type scored struct {
+		song  SongLite
+		score int
+	}
+	var out []scored
+	byKey := make(map[string]int) // SongKey or ID -> index in out
+	for _, s := range songs {
+		sc := scored{song: s, score: scoreSong(s, whole, terms)}
+		idKey, songKey := "id:"+s.ID, SongKey(s)
+		n, dup := byKey[idKey]
+		if !dup {
+			n, dup = byKey[songKey]
+		}
+		if dup {
+			if sc.score > out[n].score {
+				out[n] = sc
+			}
+		} else {
+			n = len(out)
+			out = append(out, sc)
+		}
+		byKey[idKey], byKey[songKey] = n, n
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	ranked := make([]SongLite, len(out))
+	for n, sc := range out {
+		ranked[n] = sc.song
+	}
+	return ranked
+}
+
+func scoreSong(s SongLite, whole string, terms []string) int {
+	title := normalizeKey(s.Title)
+	titleWords := searchTerms(s.Title)
+	artistWords := searchTerms(strings.Join(append([]string{s.Artist}, s.Artists...), " "))
+
+	score := 0
+	switch {
+	case whole != "" && title == whole:
+		score += scoreExactTitle
+	case whole != "" && strings.HasPrefix(title, whole):
+		score += scoreTitlePrefix
+	}
+
+	matched := 0
+	for _, term := range terms {
+		switch {
+		case hasWordPrefix(titleWords, term):
+			score += scoreTitleTerm
+		case hasWordPrefix(artistWords, term):
+			score += scoreArtistTerm
+		case hasFuzzyWord(titleWords, term) || hasFuzzyWord(artistWords, term):
+			score += scoreFuzzyTerm
+		default:
+			continue
+		}
+		matched++
+	}
+	if len(terms) > 0 && matched == len(terms) {
+		score += scoreAllTerms
+	}
+	if s.StreamURL != "" {
+		score += scoreHasStream
+	}
+	return score
+}
+
+// searchTerms splits text into normalized words.
+func searchTerms(s string) []string {
+	var out []string
+	for _, f := range strings.Fields(s) {
+		if w := normalizeKey(f); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func hasWordPrefix(words []string, term string) bool {
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFuzzyWord allows one typo for terms of four letters or more, two for
+// eight or more.
+func hasFuzzyWord(words []string, term string) bool {
+	limit := 0
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		limit = 2
+	case n >= 4:
+		limit = 1
+	default:
+		return false
+	}
+	for _, w := range words {
+		if editDistance(w, term, limit) <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the Levenshtein distance between a and b, giving up with
+// limit+1 as soon as it is certain to exceed limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
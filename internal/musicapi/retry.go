@@ -0,0 +1,87 @@
+package musicapi
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how getJSON retries transient failures (network
+// errors, 5xx and 429). Delays grow exponentially with jitter.
+type RetryPolicy struct {
+	MaxAttempts int           // total tries, including the first
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration // cap per wait; a longer Retry-After gives up
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   300 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// StatusError is returned for non-200 API responses.
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("api status %d", e.Code)
+}
+
+// retryable reports whether err is worth another attempt. Anything that
+// isn't an HTTP status (timeouts, resets, DNS) is treated as transient.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusTooManyRequests || se.Code >= 500
+	}
+	var de *decodeError
+	return !errors.As(err, &de)
+}
+
+// decodeError marks a response that arrived but wasn't valid JSON.
+type decodeError struct{ err error }
+
+func (e *decodeError) Error() string { return "decode response: " + e.err.Error() }
+func (e *decodeError) Unwrap() error { return e.err }
+
+// backoff returns how long to wait before retry number attempt (0-based),
+// or false if the server asked for longer than the policy allows.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var se *StatusError
+	if errors.As(err, &se) && se.RetryAfter > 0 {
+		if se.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return se.RetryAfter, true
+	}
+
+	d := p.BaseDelay << attempt
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// equal jitter: half fixed, half random, so retries don't line up
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+// parseRetryAfter accepts both delay-seconds and HTTP-date forms.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package musicapi
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// CleanText tidies a metadata string from an API or a file tag: HTML
+// entities are decoded (some APIs double-encode, so up to twice), control
+// and zero-width characters are dropped and whitespace runs collapse to a
+// single space.
+func CleanText(s string) string {
+	for n := 0; n < 2 && strings.ContainsRune(s, '&'); n++ {
+		s = html.UnescapeString(s)
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// cleanURL decodes entities in a URL ("&amp;" between query parameters)
+// without touching anything else.
+func cleanURL(s string) string {
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// markdownEscaper backslash-escapes the characters Discord treats as
+// formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`",
+	"|", `\|`, ">", `\>`, "[", `\[`, "]", `\]`,
+)
+
+// EscapeMarkdown makes s render literally in Discord messages and embeds.
+// Songs keep their plain text (search queries and select menu labels
+// don't render markdown); callers escape where they format.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// sanitize cleans every string field of a song before it leaves the package.
+func (s *SongLite) sanitize() {
+	s.ID = strings.TrimSpace(s.ID)
+	s.Title = CleanText(s.Title)
+	for n, a := range s.Artists {
+		s.Artists[n] = CleanText(a)
+	}
+	s.Artist = CleanText(s.Artist)
+	s.Image = cleanURL(s.Image)
+	s.Link = cleanURL(s.Link)
+	s.StreamURL = cleanURL(s.StreamURL)
+}
+
+func (d *SongDetail) sanitize() {
+	d.SongLite.sanitize()
+	d.Album = CleanText(d.Album)
+	d.Language = CleanText(d.Language)
+	for n := range d.Qualities {
+		d.Qualities[n].Label = CleanText(d.Qualities[n].Label)
+		d.Qualities[n].URL = cleanURL(d.Qualities[n].URL)
+	}
+}
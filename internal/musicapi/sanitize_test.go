@@ -0,0 +1,101 @@
+package musicapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Tum Hi Ho", "Tum Hi Ho"},
+		{"quot", "&quot;Kesariya&quot;", `"Kesariya"`},
+		{"amp", "Simon &amp; Garfunkel", "Simon & Garfunkel"},
+		{"numeric", "Don&#039;t Stop", "Don't Stop"},
+		{"double encoded", "Rock &amp;amp; Roll &amp;quot;Live&amp;quot;", `Rock & Roll "Live"`},
+		{"literal ampersand", "AC&DC", "AC&DC"},
+		{"control chars", "Bad\x00Song\x07\x1b", "BadSong"},
+		{"zero width", "Zero\u200bWidth\u200d\ufeff", "ZeroWidth"},
+		{"bidi marks", "\u202aLeft\u202c", "Left"},
+		{"space runs", "  Too   many \t spaces  ", "Too many spaces"},
+		{"newlines", "Line one\r\n\nLine two", "Line one Line two"},
+		{"nbsp", "Non\u00a0breaking", "Non breaking"},
+		{"entity space", "A&nbsp;&nbsp;B", "A B"},
+		{"empty", "", ""},
+		{"only space", " \t\n ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanText(tt.in); got != tt.want {
+				t.Errorf("CleanText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{`back\slash`, `back\\slash`},
+		{"*bold*", `\*bold\*`},
+		{"_italic_", `\_italic\_`},
+		{"~~strike~~", `\~\~strike\~\~`},
+		{"`code`", "\\`code\\`"},
+		{"||spoiler||", `\|\|spoiler\|\|`},
+		{"> quote", `\> quote`},
+		{"[link](url)", `\[link\](url)`},
+		{"*_~`|>[]", "\\*\\_\\~\\`\\|\\>\\[\\]"},
+	}
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.in); got != tt.want {
+			t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSearchSongsSanitizes(t *testing.T) {
+	const payload = `{"data": {"results": [{
+		"id": " abc123 ",
+		"name": "Tere &amp;quot;Bina&amp;quot;\u200b  Live",
+		"primaryArtists": "A.R. Rahman &amp;amp; Chinmayi,  Murtuza\u0007 Khan",
+		"image": "https://img.example/x.jpg?w=500&amp;h=500",
+		"downloadUrl": [
+			{"quality": "96kbps", "url": "https://cdn.example/96.mp4?a=1&amp;b=2"},
+			{"quality": "320kbps", "url": "https://cdn.example/320.mp4?a=1&amp;b=2"}
+		]
+	}]}}`
+
+	var raw any
+	if err := json.Unmarshal([]byte(payload), &raw); err != nil {
+		t.Fatal(err)
+	}
+	songs, err := NormalizeSearchSongs(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 1 {
+		t.Fatalf("got %d songs, want 1", len(songs))
+	}
+
+	s := songs[0]
+	checks := []struct {
+		field, got, want string
+	}{
+		{"ID", s.ID, "abc123"},
+		{"Title", s.Title, `Tere "Bina" Live`},
+		{"Artist", s.Artist, "A.R. Rahman, Chinmayi & Murtuza Khan"},
+		{"Image", s.Image, "https://img.example/x.jpg?w=500&h=500"},
+		{"StreamURL", s.StreamURL, "https://cdn.example/320.mp4?a=1&b=2"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.field, c.got, c.want)
+		}
+	}
+}